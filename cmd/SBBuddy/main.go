@@ -4,7 +4,6 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -13,23 +12,161 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"SBBuddy/internal/api"
+	"SBBuddy/internal/config"
+	"SBBuddy/internal/export"
+	"SBBuddy/internal/store"
 	"SBBuddy/internal/ui"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "monitor" {
+		runMonitor(os.Args[2:])
+		return
+	}
 	args := insertDefaultRandom(os.Args[1:])
 
 	randomVia := flag.Int("R", 0, "Get a random connection. Value specifies number of via stations")
-	station := flag.String("T", "", "Lookup timetable for the given station")
 	date := flag.String("d", "", "Date for lookup (YYYY-MM-DD or DD.MM.YYYY)")
 	tm := flag.String("t", "", "Time for lookup (HH:mm)")
 	arrival := flag.Bool("a", false, "Use arrival time instead of departure")
+	favorite := flag.String("F", "", "Open a saved favorite station or route by nickname")
+	saveFavorite := flag.String("save-favorite", "", "Save the -T station or -C route as a favorite with the given nickname")
+	icsPath := flag.String("ics", "", "Write the first connection found by -C or -R to the given .ics calendar file")
+	output := flag.String("output", export.FormatTable, "Output format for -T, -C and -R: table, json, ndjson, csv or tsv")
+	class := flag.Int("class", 0, "Travel class (1 or 2) for occupancy forecasts, defaults to travel_class from the config")
+	arrivals := flag.Bool("arrivals", false, "Show the trains arriving at the -T station instead of departing ones")
+	walk := flag.Int("walk", 0, "Minutes it takes to walk to the -T station: the board starts with the departures you can still catch, saved by --save-favorite")
+	limit := flag.Int("limit", 0, "Number of departures fetched by -T and per stationboard page, defaults to stationboard_limit from the config")
+	direct := flag.Bool("direct", false, "Only show connections without changes")
+	sleeper := flag.Bool("sleeper", false, "Only show night trains with sleeping cars")
+	couchette := flag.Bool("couchette", false, "Only show night trains with couchettes")
+	bike := flag.Bool("bike", false, "Only show connections that allow bicycles")
+	accessibility := flag.String("accessibility", "", "Require step-free boarding: independent_boarding, assisted_boarding or advanced_notice")
+	count := flag.Int("count", 0, "Number of connections fetched by -C and -R and per page in the TUI (1-16), defaults to connection_limit from the config")
+	page := flag.Int("page", 0, "Show further connections of -C and -R: 1 the following page of results, 2 the one after; for earlier connections search an earlier -t")
+	transport := flag.String("transport", "", "Comma-separated transport types (train, tram, ship, bus, cableway) for -T, -C and -R, defaults to the filter saved in the TUI")
 
+	var stations multiFlag
+	flag.Var(&stations, "T", "Lookup timetable for the given station, repeat to merge the departures of several stations into one board")
 	var connections multiFlag
 	flag.Var(&connections, "C", "Specify origin and destination; first and last are origin and destination, all others are via stations")
 
 	flag.CommandLine.Parse(args)
 
+	if !export.ValidFormat(*output) {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format %q\n", *output)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+		os.Exit(1)
+	}
+	switch *class {
+	case 0:
+		*class = cfg.TravelClass
+	case 1, 2:
+	default:
+		fmt.Fprintln(os.Stderr, "Error: --class must be 1 or 2")
+		os.Exit(1)
+	}
+	switch {
+	case *limit == 0:
+		*limit = cfg.StationboardLimit
+	case *limit < 0 || *limit > 100:
+		fmt.Fprintln(os.Stderr, "Error: --limit must be between 1 and 100")
+		os.Exit(1)
+	}
+	transportations, err := api.ParseTransportations(*transport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --transport: %v\n", err)
+		os.Exit(1)
+	}
+	level, err := api.ParseAccessibility(*accessibility)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --accessibility: %v\n", err)
+		os.Exit(1)
+	}
+	if *walk < 0 || *walk > store.MaxWalkMinutes {
+		fmt.Fprintf(os.Stderr, "Error: --walk must be between 0 and %d\n", store.MaxWalkMinutes)
+		os.Exit(1)
+	}
+	if *page < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --page must not be negative, search an earlier -t for earlier connections")
+		os.Exit(1)
+	}
+	switch {
+	case *count == 0:
+		*count = cfg.ConnectionLimit
+	case *count < 0 || *count > api.MaxConnectionLimit:
+		fmt.Fprintf(os.Stderr, "Error: --count must be between 1 and %d\n", api.MaxConnectionLimit)
+		os.Exit(1)
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	st, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *transport == "" {
+		transportations = st.Preferences.Transportations
+	}
+	sbOpts := api.StationboardOptions{Limit: *limit, Arrivals: *arrivals, Transportations: transportations}
+	connOpts := api.ConnectionOptions{
+		Transportations: transportations,
+		Direct:          *direct,
+		Sleeper:         *sleeper,
+		Couchette:       *couchette,
+		Bike:            *bike,
+		Accessibility:   level,
+		Limit:           *count,
+		Page:            *page,
+	}
+
+	if *favorite != "" {
+		fav, ok := st.Favorites.Find(*favorite)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: no favorite named %q\n", *favorite)
+			os.Exit(1)
+		}
+		if fav.IsRoute() {
+			connections = fav.Stations
+		} else {
+			stations = multiFlag{fav.From()}
+			if *walk == 0 {
+				*walk = fav.WalkMinutes
+			}
+		}
+	}
+
+	if *saveFavorite != "" {
+		fav := store.Favorite{Name: *saveFavorite}
+		switch {
+		case len(connections) >= 2:
+			fav.Stations = connections
+		case len(stations) > 1:
+			fmt.Fprintln(os.Stderr, "Error: --save-favorite takes a single -T station")
+			os.Exit(1)
+		case len(stations) == 1:
+			fav.Stations = []string{stations[0]}
+			fav.WalkMinutes = *walk
+		default:
+			fmt.Fprintln(os.Stderr, "Error: --save-favorite needs -T or at least two -C stations")
+			os.Exit(1)
+		}
+		st.Favorites.Add(fav)
+		if err := st.Favorites.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Saved favorite %q\n", *saveFavorite)
+	}
+
 	var randomFlag bool
 	flag.CommandLine.Visit(func(f *flag.Flag) {
 		if f.Name == "R" {
@@ -42,7 +179,6 @@ func main() {
 			os.Exit(1)
 		}
 
-		client := api.NewClient(&http.Client{Timeout: 8 * time.Second})
 		var from, to string
 		var via []string
 
@@ -89,14 +225,14 @@ func main() {
 		sp := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 		sp.Suffix = " Fetching..."
 		sp.Start()
-		cr, err := client.FetchConnections(context.Background(), from, to, via)
+		cr, err := client.FetchConnectionsWith(context.Background(), from, to, via, "", "", connOpts)
 		sp.Stop()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println(ui.FormatConnectionsTitle(from, via, to, ""))
-		fmt.Print(ui.RenderConnectionsTable(cr))
+		printConnections(*output, *class, ui.FormatConnectionsTitle(from, via, to, ""), cr)
+		writeICS(*icsPath, cr)
 		return
 	}
 
@@ -112,7 +248,6 @@ func main() {
 			os.Exit(1)
 		}
 
-		client := api.NewClient(&http.Client{Timeout: 8 * time.Second})
 		sp := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 		sp.Suffix = " Fetching..."
 		sp.Start()
@@ -125,9 +260,10 @@ func main() {
 			via = connections[1 : len(connections)-1]
 		}
 		if *date != "" || *tm != "" {
-			cr, err = client.FetchConnectionsAt(context.Background(), from, to, via, dateStr, timeStr, *arrival)
+			connOpts.Arrival = *arrival
+			cr, err = client.FetchConnectionsWith(context.Background(), from, to, via, dateStr, timeStr, connOpts)
 		} else {
-			cr, err = client.FetchConnections(context.Background(), from, to, via)
+			cr, err = client.FetchConnectionsWith(context.Background(), from, to, via, "", "", connOpts)
 		}
 		sp.Stop()
 		if err != nil {
@@ -138,12 +274,16 @@ func main() {
 		if *date != "" || *tm != "" {
 			info = fmt.Sprintf("%s %s", ui.FormatDateDisplay(dateStr), timeStr)
 		}
-		fmt.Println(ui.FormatConnectionsTitle(from, via, to, info))
-		fmt.Print(ui.RenderConnectionsTable(cr))
+		printConnections(*output, *class, ui.FormatConnectionsTitle(from, via, to, info), cr)
+		writeICS(*icsPath, cr)
 		return
 	}
 
-	if *station != "" {
+	if len(stations) > 0 {
+		if *walk > 0 && len(stations) > 1 {
+			fmt.Fprintln(os.Stderr, "Error: --walk takes a single -T station")
+			os.Exit(1)
+		}
 		dateStr, err := ui.ParseDateInput(*date)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Invalid date: %v\n", err)
@@ -155,38 +295,132 @@ func main() {
 			os.Exit(1)
 		}
 
-		client := api.NewClient(&http.Client{Timeout: 8 * time.Second})
 		sp := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 		sp.Suffix = " Fetching..."
 		sp.Start()
 
-		var sb *api.StationboardResponse
-		if *date != "" || *tm != "" {
-			sb, err = client.FetchStationboardAt(context.Background(), *station, dateStr, timeStr)
-		} else {
-			sb, err = client.FetchStationboard(context.Background(), *station)
+		at, atTime := "", ""
+		now := time.Now()
+		walking := *walk > 0 && !*arrivals && *date == "" && *tm == ""
+		switch {
+		case *date != "" || *tm != "":
+			at, atTime = dateStr, timeStr
+		case walking:
+			start := ui.WalkStart(now, time.Duration(*walk)*time.Minute)
+			at, atTime = start.Format("2006-01-02"), start.Format("15:04")
 		}
+		sb, err := client.FetchStationboards(context.Background(), stations, at, atTime, sbOpts)
 		sp.Stop()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		title := fmt.Sprintf("Stationboard for %s", *station)
+		if walking {
+			// skip what is expected to leave before you can be at the station
+			ui.DropUnreachable(sb, stations[0], now, time.Duration(*walk)*time.Minute)
+		}
+		title := fmt.Sprintf("Stationboard for %s", strings.Join(stations, " + "))
+		if *arrivals {
+			title = fmt.Sprintf("Arrivals at %s", strings.Join(stations, " + "))
+		}
 		if *date != "" || *tm != "" {
 			title += fmt.Sprintf(" on %s %s", ui.FormatDateDisplay(dateStr), timeStr)
+		} else if walking {
+			title += fmt.Sprintf(" (%d min walk, from %s)", *walk, now.Add(time.Duration(*walk)*time.Minute).Format("15:04"))
 		}
-		fmt.Println(title)
-		fmt.Print(ui.RenderStationboardTable(sb))
+		printStationboard(*output, title, sb)
 		return
 	}
 
-	p := tea.NewProgram(ui.InitialModel())
+	model := ui.NewModel(client, st)
+	model.SetTravelClass(*class)
+	model.SetStationboardLimit(*limit)
+	if *transport != "" {
+		model.SetTransportations(transportations)
+	}
+	model.SetConnectionOptions(connOpts)
+	model.SetConnectionLimit(*count)
+	model.SetLiveInterval(cfg.LiveInterval)
+	p := tea.NewProgram(model)
 	if err := p.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// printConnections writes connections to stdout, either as the titled table
+// or in the requested machine-readable format.
+func printConnections(format string, class int, title string, cr *api.ConnectionsResponse) {
+	warnCached(cr.CachedAt)
+	if format == export.FormatTable {
+		fmt.Println(title)
+		fmt.Print(ui.RenderConnectionsTableForClass(cr, class))
+		return
+	}
+	if err := export.WriteConnections(os.Stdout, format, cr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// openStore opens the user data store. Without a usable data directory the
+// store lives in memory only.
+func openStore(cfg config.Config) (*store.Store, error) {
+	dir, err := cfg.DataDirectory()
+	if err != nil {
+		return store.Open("")
+	}
+	return store.Open(dir)
+}
+
+// warnCached tells the user on stderr that offline cached results are shown,
+// keeping stdout clean for machine-readable output.
+func warnCached(cachedAt time.Time) {
+	if !cachedAt.IsZero() {
+		fmt.Fprintf(os.Stderr, "Warning: offline, showing cached results from %s\n", cachedAt.Local().Format("02.01.2006 15:04"))
+	}
+}
+
+// writeICS exports the first connection as an iCalendar file when a path was
+// given with --ics.
+func writeICS(path string, cr *api.ConnectionsResponse) {
+	if path == "" {
+		return
+	}
+	if cr == nil || len(cr.Connections) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no connection to export")
+		os.Exit(1)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	err = export.WriteICS(f, &cr.Connections[0], time.Now())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printStationboard writes a stationboard to stdout, either as the titled
+// table or in the requested machine-readable format.
+func printStationboard(format, title string, sb *api.StationboardResponse) {
+	warnCached(sb.CachedAt)
+	if format == export.FormatTable {
+		fmt.Println(title)
+		fmt.Print(ui.RenderStationboardTable(sb))
+		return
+	}
+	if err := export.WriteStationboard(os.Stdout, format, sb); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 type multiFlag []string
 
 func (m *multiFlag) String() string {
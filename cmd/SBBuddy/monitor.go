@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"SBBuddy/internal/api"
+	"SBBuddy/internal/config"
+	"SBBuddy/internal/ui"
+)
+
+// runMonitor implements `SBBuddy monitor`: live stationboards of the -T
+// stations side by side on the whole terminal, e.g. for a lobby display.
+func runMonitor(args []string) {
+	fs := flag.NewFlagSet("monitor", flag.ExitOnError)
+	var stations multiFlag
+	fs.Var(&stations, "T", "Station to show, repeat for several boards side by side")
+	arrivals := fs.Bool("arrivals", false, "Show arriving instead of departing trains")
+	limit := fs.Int("limit", 0, "Number of departures fetched per board, defaults to stationboard_limit from the config")
+	transport := fs.String("transport", "", "Comma-separated transport types (train, tram, ship, bus, cableway)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: SBBuddy monitor -T station [-T station ...]\n\nFull-screen departure monitor, quit with ctrl+c.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(stations) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+		os.Exit(1)
+	}
+	switch {
+	case *limit == 0:
+		*limit = cfg.StationboardLimit
+	case *limit < 0 || *limit > 100:
+		fmt.Fprintln(os.Stderr, "Error: --limit must be between 1 and 100")
+		os.Exit(1)
+	}
+	transportations, err := api.ParseTransportations(*transport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --transport: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	monitor := ui.NewMonitor(client, stations, api.StationboardOptions{Limit: *limit, Arrivals: *arrivals, Transportations: transportations})
+	monitor.SetInterval(cfg.LiveInterval)
+	p := tea.NewProgram(monitor, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CachedProvider wraps another Provider with a persistent on-disk cache.
+// Fresh entries are served without contacting the backend. When the backend
+// fails (e.g. no network) an expired entry younger than maxStale is returned
+// instead and marked via the CachedAt field of the response.
+type CachedProvider struct {
+	inner        Provider
+	dir          string
+	locationsTTL time.Duration
+	timetableTTL time.Duration
+	maxStale     time.Duration
+	now          func() time.Time
+}
+
+// cacheMode changes how CachedProvider handles the requests of a context.
+type cacheMode int
+
+const (
+	cacheNoStore cacheMode = 1 << iota
+	cacheRefresh
+)
+
+type cacheModeKey struct{}
+
+// NoCacheStore returns a context whose responses CachedProvider serves from
+// the cache when possible but never stores, e.g. for the throwaway prefixes
+// looked up while typing.
+func NoCacheStore(ctx context.Context) context.Context {
+	return withCacheMode(ctx, cacheNoStore)
+}
+
+// RefreshCache returns a context whose requests CachedProvider always sends
+// to the backend, e.g. for boards refreshed more often than the cache
+// expires. Responses are still stored, and served when the backend fails.
+func RefreshCache(ctx context.Context) context.Context {
+	return withCacheMode(ctx, cacheRefresh)
+}
+
+func withCacheMode(ctx context.Context, mode cacheMode) context.Context {
+	old, _ := ctx.Value(cacheModeKey{}).(cacheMode)
+	return context.WithValue(ctx, cacheModeKey{}, old|mode)
+}
+
+func cacheModeOf(ctx context.Context) cacheMode {
+	mode, _ := ctx.Value(cacheModeKey{}).(cacheMode)
+	return mode
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewCachedProvider caches the responses of inner in dir. Locations are kept
+// for locationsTTL, stationboards and connections for timetableTTL.
+func NewCachedProvider(inner Provider, dir string, locationsTTL, timetableTTL, maxStale time.Duration) *CachedProvider {
+	return &CachedProvider{
+		inner:        inner,
+		dir:          dir,
+		locationsTTL: locationsTTL,
+		timetableTTL: timetableTTL,
+		maxStale:     maxStale,
+		now:          time.Now,
+	}
+}
+
+func (p *CachedProvider) path(kind string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return filepath.Join(p.dir, kind+"-"+hex.EncodeToString(sum[:12])+".json")
+}
+
+func (p *CachedProvider) read(path string) (*cacheEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// write stores v atomically so concurrent readers never see partial files.
+func (p *CachedProvider) write(path string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cacheEntry{FetchedAt: p.now(), Data: raw})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(p.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cached returns a fresh cache entry, otherwise calls fetch and stores the
+// result unless ctx says otherwise. If fetch fails, a stale entry is
+// returned together with the time it was fetched.
+func cached[T any](ctx context.Context, p *CachedProvider, path string, ttl time.Duration, fetch func() (T, error)) (T, time.Time, error) {
+	var zero T
+	mode := cacheModeOf(ctx)
+	entry, readErr := p.read(path)
+	if readErr == nil && mode&cacheRefresh == 0 && p.now().Sub(entry.FetchedAt) < ttl {
+		var v T
+		if err := json.Unmarshal(entry.Data, &v); err == nil {
+			return v, time.Time{}, nil
+		}
+	}
+
+	v, err := fetch()
+	if err == nil {
+		if mode&cacheNoStore == 0 {
+			// A failing cache write must not hide a successful response.
+			_ = p.write(path, v)
+		}
+		return v, time.Time{}, nil
+	}
+	if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled {
+		return zero, time.Time{}, err
+	}
+	if readErr == nil && p.now().Sub(entry.FetchedAt) < ttl+p.maxStale {
+		var stale T
+		if jsonErr := json.Unmarshal(entry.Data, &stale); jsonErr == nil {
+			return stale, entry.FetchedAt, nil
+		}
+	}
+	return zero, time.Time{}, err
+}
+
+func (p *CachedProvider) Locations(ctx context.Context, query string) ([]Location, error) {
+	path := p.path("locations", strings.ToLower(strings.TrimSpace(query)))
+	stations, _, err := cached(ctx, p, path, p.locationsTTL, func() ([]Location, error) {
+		return p.inner.Locations(ctx, query)
+	})
+	return stations, err
+}
+
+func (p *CachedProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	path := p.path("stationboard", strings.ToLower(strings.TrimSpace(station)), date, timeStr, fmt.Sprintf("limit:%d", opts.Limit), fmt.Sprintf("arrivals:%t", opts.Arrivals), "transport:"+strings.Join(opts.Transportations, ","))
+	sb, cachedAt, err := cached(ctx, p, path, p.timetableTTL, func() (*StationboardResponse, error) {
+		return p.inner.Stationboard(ctx, station, date, timeStr, opts)
+	})
+	if sb != nil {
+		sb.CachedAt = cachedAt
+	}
+	return sb, err
+}
+
+func (p *CachedProvider) Connections(ctx context.Context, from, to string, via []string, date, timeStr string, opts ConnectionOptions) (*ConnectionsResponse, error) {
+	parts := []string{strings.ToLower(strings.TrimSpace(from)), strings.ToLower(strings.TrimSpace(to)), date, timeStr}
+	if opts.Arrival {
+		parts = append(parts, "arrival")
+	}
+	for _, v := range via {
+		parts = append(parts, "via:"+strings.ToLower(strings.TrimSpace(v)))
+	}
+	if len(opts.Transportations) > 0 {
+		parts = append(parts, "transport:"+strings.Join(opts.Transportations, ","))
+	}
+	if q := searchOptionsQuery(opts); q != "" {
+		parts = append(parts, "options:"+q)
+	}
+	if opts.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit:%d", opts.Limit))
+	}
+	path := p.path("connections", parts...)
+	cr, cachedAt, err := cached(ctx, p, path, p.timetableTTL, func() (*ConnectionsResponse, error) {
+		return p.inner.Connections(ctx, from, to, via, date, timeStr, opts)
+	})
+	if cr != nil {
+		cr.CachedAt = cachedAt
+	}
+	return cr, err
+}
+
+// Prune removes cache files that are too old to be served even offline.
+func (p *CachedProvider) Prune() error {
+	entries, err := os.ReadDir(p.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := p.now()
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		ttl := p.timetableTTL
+		if strings.HasPrefix(e.Name(), "locations-") {
+			ttl = p.locationsTTL
+		}
+		if now.Sub(info.ModTime()) > ttl+p.maxStale {
+			os.Remove(filepath.Join(p.dir, e.Name()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingProvider serves fixtures and counts backend calls; setting fail
+// simulates being offline.
+type countingProvider struct {
+	*FixtureProvider
+	calls int
+	fail  bool
+}
+
+func (p *countingProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	p.calls++
+	if p.fail {
+		return nil, errors.New("offline")
+	}
+	return p.FixtureProvider.Stationboard(ctx, station, date, timeStr, opts)
+}
+
+func (p *countingProvider) Locations(ctx context.Context, query string) ([]Location, error) {
+	p.calls++
+	if p.fail {
+		return nil, errors.New("offline")
+	}
+	return p.FixtureProvider.Locations(ctx, query)
+}
+
+func newTestCache(t *testing.T) (*CachedProvider, *countingProvider, *time.Time) {
+	inner := &countingProvider{FixtureProvider: NewFixtureProvider(filepath.Join("..", "..", "testdata"))}
+	p := NewCachedProvider(inner, t.TempDir(), time.Hour, time.Minute, 24*time.Hour)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	return p, inner, &now
+}
+
+func TestCachedProviderFresh(t *testing.T) {
+	p, inner, _ := newTestCache(t)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		sb, err := p.Stationboard(ctx, "Chur", "", "", StationboardOptions{})
+		if err != nil || len(sb.Stationboard) != 1 {
+			t.Fatalf("Stationboard error: %v", err)
+		}
+		if !sb.CachedAt.IsZero() {
+			t.Errorf("fresh response should not be marked as cached")
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 backend call, got %d", inner.calls)
+	}
+}
+
+func TestCachedProviderOfflineFallback(t *testing.T) {
+	p, inner, now := newTestCache(t)
+	ctx := context.Background()
+	if _, err := p.Stationboard(ctx, "Chur", "", "", StationboardOptions{}); err != nil {
+		t.Fatalf("Stationboard error: %v", err)
+	}
+	fetchedAt := *now
+
+	*now = now.Add(10 * time.Minute)
+	inner.fail = true
+	sb, err := p.Stationboard(ctx, "Chur", "", "", StationboardOptions{})
+	if err != nil {
+		t.Fatalf("expected stale fallback, got %v", err)
+	}
+	if !sb.CachedAt.Equal(fetchedAt) {
+		t.Errorf("expected CachedAt %v got %v", fetchedAt, sb.CachedAt)
+	}
+
+	*now = now.Add(48 * time.Hour)
+	if _, err := p.Stationboard(ctx, "Chur", "", "", StationboardOptions{}); err == nil {
+		t.Error("expected error once the entry is older than maxStale")
+	}
+}
+
+func TestCachedProviderLocationsTTL(t *testing.T) {
+	p, inner, now := newTestCache(t)
+	ctx := context.Background()
+	p.Locations(ctx, "Chur")
+	*now = now.Add(30 * time.Minute)
+	p.Locations(ctx, "chur ")
+	if inner.calls != 1 {
+		t.Errorf("expected normalized cache hit, got %d calls", inner.calls)
+	}
+	*now = now.Add(time.Hour)
+	p.Locations(ctx, "Chur")
+	if inner.calls != 2 {
+		t.Errorf("expected refetch after TTL, got %d calls", inner.calls)
+	}
+}
+
+func TestCachedProviderNoStore(t *testing.T) {
+	p, inner, _ := newTestCache(t)
+	ctx := NoCacheStore(context.Background())
+	p.Locations(ctx, "Chu")
+	p.Locations(ctx, "Chu")
+	if inner.calls != 2 {
+		t.Errorf("expected every lookup to reach the backend, got %d calls", inner.calls)
+	}
+	if entries, _ := os.ReadDir(p.dir); len(entries) != 0 {
+		t.Errorf("expected nothing stored, got %d files", len(entries))
+	}
+
+	// entries stored by other lookups are still served
+	p.Locations(context.Background(), "Chur")
+	p.Locations(ctx, "Chur")
+	if inner.calls != 3 {
+		t.Errorf("expected a cache hit, got %d calls", inner.calls)
+	}
+}
+
+func TestCachedProviderRefresh(t *testing.T) {
+	p, inner, now := newTestCache(t)
+	ctx := RefreshCache(context.Background())
+	for i := 0; i < 2; i++ {
+		if _, err := p.Stationboard(ctx, "Chur", "", "", StationboardOptions{}); err != nil {
+			t.Fatalf("Stationboard error: %v", err)
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected every refresh to reach the backend, got %d calls", inner.calls)
+	}
+
+	// the stored board is still served offline
+	fetchedAt := *now
+	*now = now.Add(10 * time.Second)
+	inner.fail = true
+	sb, err := p.Stationboard(ctx, "Chur", "", "", StationboardOptions{})
+	if err != nil || !sb.CachedAt.Equal(fetchedAt) {
+		t.Errorf("expected the stored board as fallback, got %v", err)
+	}
+}
+
+func TestCachedProviderPrune(t *testing.T) {
+	p, _, now := newTestCache(t)
+	if _, err := p.Stationboard(context.Background(), "Chur", "", "", StationboardOptions{}); err != nil {
+		t.Fatalf("Stationboard error: %v", err)
+	}
+	*now = time.Now().Add(48 * time.Hour)
+	if err := p.Prune(); err != nil {
+		t.Fatalf("Prune error: %v", err)
+	}
+	entries, _ := os.ReadDir(p.dir)
+	if len(entries) != 0 {
+		t.Errorf("expected cache to be pruned, %d files left", len(entries))
+	}
+}
@@ -2,11 +2,8 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -14,63 +11,25 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-const (
-	baseURL         = "https://transport.opendata.ch/v1"
-	endpointStation = "/stationboard"
-	endpointConn    = "/connections"
-	endpointLoc     = "/locations"
-)
-
+// Client wraps a Provider with the caching and tea.Cmd helpers used by the UI
+// and the CLI. It is safe for concurrent use.
 type Client struct {
-	httpClient   *http.Client
+	provider     Provider
 	stationCache map[string][]Location
 	cacheMutex   sync.RWMutex
 }
 
+// NewClient creates a client backed by the opendata.ch API.
 func NewClient(httpClient *http.Client) *Client {
-	if httpClient == nil {
-		httpClient = &http.Client{Timeout: 8 * time.Second}
-	}
-	return &Client{
-		httpClient:   httpClient,
-		stationCache: make(map[string][]Location),
-	}
+	return NewClientWithProvider(NewOpenDataProvider(httpClient, DefaultBaseURL, DefaultUserAgent))
 }
 
-func (c *Client) makeHTTPRequest(ctx context.Context, requestURL string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Accept-Encoding", "gzip, deflate")
-	req.Header.Set("User-Agent", "SwissTransportTUI/1.0")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	if len(body) == 0 {
-		return nil, fmt.Errorf("received empty response from API")
-	}
-
-	trimmed := strings.TrimSpace(string(body))
-	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
-		return nil, fmt.Errorf("received non-JSON response: %s", string(body)[:min(100, len(body))])
+// NewClientWithProvider creates a client backed by the given provider.
+func NewClientWithProvider(p Provider) *Client {
+	return &Client{
+		provider:     p,
+		stationCache: make(map[string][]Location),
 	}
-
-	return body, nil
 }
 
 func min(a, b int) int {
@@ -81,6 +40,14 @@ func min(a, b int) int {
 }
 
 func (c *Client) ValidateStation(query string) ([]Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.SearchStations(ctx, query)
+}
+
+// SearchStations looks up stations matching query like ValidateStation but
+// lets the caller cancel the lookup through ctx.
+func (c *Client) SearchStations(ctx context.Context, query string) ([]Location, error) {
 	if len(strings.TrimSpace(query)) < 2 {
 		return nil, fmt.Errorf("query too short")
 	}
@@ -94,24 +61,13 @@ func (c *Client) ValidateStation(query string) ([]Location, error) {
 	}
 	c.cacheMutex.RUnlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	encodedQuery := url.QueryEscape(strings.TrimSpace(query))
-	requestURL := fmt.Sprintf("%s%s?query=%s&type=station", baseURL, endpointLoc, encodedQuery)
-
-	body, err := c.makeHTTPRequest(ctx, requestURL)
+	stations, err := c.provider.Locations(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	var result LocationResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %v", err)
-	}
-
 	c.cacheMutex.Lock()
-	c.stationCache[normalized] = result.Stations
+	c.stationCache[normalized] = stations
 	if len(c.stationCache) > 50 {
 		for k := range c.stationCache {
 			delete(c.stationCache, k)
@@ -122,7 +78,7 @@ func (c *Client) ValidateStation(query string) ([]Location, error) {
 	}
 	c.cacheMutex.Unlock()
 
-	return result.Stations, nil
+	return stations, nil
 }
 
 func FindExactMatch(query string, suggestions []Location) *Location {
@@ -135,125 +91,96 @@ func FindExactMatch(query string, suggestions []Location) *Location {
 	return nil
 }
 
+// LocationsMsg carries the stations found for Query by SearchStationsCmd.
+type LocationsMsg struct {
+	Query    string
+	Stations []Location
+	Err      error
+}
+
+// StationboardMsg carries the result of a stationboard fetch. ID echoes the
+// request ID passed to the Cmd so callers can drop superseded results.
 type StationboardMsg struct {
+	ID           int
 	Stationboard *StationboardResponse
 	Err          error
 }
 
+// ConnectionsMsg carries the result of a connections fetch, see
+// StationboardMsg for ID.
 type ConnectionsMsg struct {
+	ID          int
 	Connections *ConnectionsResponse
 	Err         error
 }
 
 func (c *Client) FetchStationboard(ctx context.Context, station string) (*StationboardResponse, error) {
-	encoded := url.QueryEscape(strings.TrimSpace(station))
-	requestURL := fmt.Sprintf("%s%s?station=%s&limit=10", baseURL, endpointStation, encoded)
-
-	body, err := c.makeHTTPRequest(ctx, requestURL)
-	if err != nil {
-		return nil, err
-	}
-
-	var sb StationboardResponse
-	if err := json.Unmarshal(body, &sb); err != nil {
-		return nil, fmt.Errorf("failed to parse stationboard JSON: %v", err)
-	}
-	return &sb, nil
+	return c.provider.Stationboard(ctx, station, "", "", StationboardOptions{})
 }
 
 func (c *Client) FetchStationboardAt(ctx context.Context, station, date, timeStr string) (*StationboardResponse, error) {
-	encoded := url.QueryEscape(strings.TrimSpace(station))
-	requestURL := fmt.Sprintf("%s%s?station=%s&limit=10", baseURL, endpointStation, encoded)
-	if date != "" && timeStr != "" {
-		dt := url.QueryEscape(fmt.Sprintf("%s %s", date, timeStr))
-		requestURL += "&datetime=" + dt
-	}
-
-	body, err := c.makeHTTPRequest(ctx, requestURL)
-	if err != nil {
-		return nil, err
-	}
+	return c.provider.Stationboard(ctx, station, date, timeStr, StationboardOptions{})
+}
 
-	var sb StationboardResponse
-	if err := json.Unmarshal(body, &sb); err != nil {
-		return nil, fmt.Errorf("failed to parse stationboard JSON: %v", err)
+// FetchStationboardWith fetches a stationboard with explicit options. Empty
+// date and timeStr request the current board.
+func (c *Client) FetchStationboardWith(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	sb, err := c.provider.Stationboard(ctx, station, date, timeStr, opts)
+	if sb != nil {
+		sb.Arrivals = opts.Arrivals
 	}
-	return &sb, nil
+	return sb, err
 }
 
 func (c *Client) FetchConnections(ctx context.Context, from, to string, via []string) (*ConnectionsResponse, error) {
-	encodedFrom := url.QueryEscape(strings.TrimSpace(from))
-	encodedTo := url.QueryEscape(strings.TrimSpace(to))
-	requestURL := fmt.Sprintf("%s%s?from=%s&to=%s&limit=5", baseURL, endpointConn, encodedFrom, encodedTo)
-	for _, v := range via {
-		requestURL += "&via[]=" + url.QueryEscape(strings.TrimSpace(v))
-	}
-
-	body, err := c.makeHTTPRequest(ctx, requestURL)
-	if err != nil {
-		return nil, err
-	}
-
-	var cr ConnectionsResponse
-	if err := json.Unmarshal(body, &cr); err != nil {
-		return nil, fmt.Errorf("failed to parse connections JSON: %v", err)
-	}
-	return &cr, nil
+	return c.provider.Connections(ctx, from, to, via, "", "", ConnectionOptions{})
 }
 
 func (c *Client) FetchConnectionsAt(ctx context.Context, from, to string, via []string, date, timeStr string, arrival bool) (*ConnectionsResponse, error) {
-	encodedFrom := url.QueryEscape(strings.TrimSpace(from))
-	encodedTo := url.QueryEscape(strings.TrimSpace(to))
-	requestURL := fmt.Sprintf("%s%s?from=%s&to=%s&limit=5", baseURL, endpointConn, encodedFrom, encodedTo)
-	for _, v := range via {
-		requestURL += "&via[]=" + url.QueryEscape(strings.TrimSpace(v))
-	}
-	if date != "" {
-		requestURL += "&date=" + url.QueryEscape(date)
-	}
-	if timeStr != "" {
-		requestURL += "&time=" + url.QueryEscape(timeStr)
-	}
-	if arrival {
-		requestURL += "&isArrivalTime=1"
-	}
+	return c.provider.Connections(ctx, from, to, via, date, timeStr, ConnectionOptions{Arrival: arrival})
+}
 
-	body, err := c.makeHTTPRequest(ctx, requestURL)
-	if err != nil {
-		return nil, err
-	}
+// FetchConnectionsWith searches connections with the given options. Empty
+// date and timeStr search from now on.
+func (c *Client) FetchConnectionsWith(ctx context.Context, from, to string, via []string, date, timeStr string, opts ConnectionOptions) (*ConnectionsResponse, error) {
+	return c.provider.Connections(ctx, from, to, via, date, timeStr, opts)
+}
 
-	var cr ConnectionsResponse
-	if err := json.Unmarshal(body, &cr); err != nil {
-		return nil, fmt.Errorf("failed to parse connections JSON: %v", err)
+// SearchStationsCmd resolves query in the background. Cancelling ctx aborts
+// the lookup; like ValidateStation it gives up after five seconds.
+func (c *Client) SearchStationsCmd(ctx context.Context, query string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		stations, err := c.SearchStations(ctx, query)
+		return LocationsMsg{Query: query, Stations: stations, Err: err}
 	}
-	return &cr, nil
 }
 
-func (c *Client) FetchStationboardCmd(station string) tea.Cmd {
+func (c *Client) FetchStationboardCmd(ctx context.Context, id int, station string, opts StationboardOptions) tea.Cmd {
 	return func() tea.Msg {
-		sb, err := c.FetchStationboard(context.Background(), station)
-		return StationboardMsg{sb, err}
+		sb, err := c.FetchStationboardWith(ctx, station, "", "", opts)
+		return StationboardMsg{id, sb, err}
 	}
 }
 
-func (c *Client) FetchStationboardAtCmd(station, date, timeStr string) tea.Cmd {
+func (c *Client) FetchStationboardAtCmd(ctx context.Context, id int, station, date, timeStr string, opts StationboardOptions) tea.Cmd {
 	return func() tea.Msg {
-		sb, err := c.FetchStationboardAt(context.Background(), station, date, timeStr)
-		return StationboardMsg{sb, err}
+		sb, err := c.FetchStationboardWith(ctx, station, date, timeStr, opts)
+		return StationboardMsg{id, sb, err}
 	}
 }
 
-func (c *Client) FetchConnectionsCmd(from, to string, via []string) tea.Cmd {
+func (c *Client) FetchConnectionsCmd(ctx context.Context, id int, from, to string, via []string, opts ConnectionOptions) tea.Cmd {
 	return func() tea.Msg {
-		cr, err := c.FetchConnections(context.Background(), from, to, via)
-		return ConnectionsMsg{cr, err}
+		cr, err := c.FetchConnectionsWith(ctx, from, to, via, "", "", opts)
+		return ConnectionsMsg{id, cr, err}
 	}
 }
 
-func (c *Client) FetchConnectionsAtCmd(from, to string, via []string, date, timeStr string, arrival bool) tea.Cmd {
+func (c *Client) FetchConnectionsAtCmd(ctx context.Context, id int, from, to string, via []string, date, timeStr string, opts ConnectionOptions) tea.Cmd {
 	return func() tea.Msg {
-		cr, err := c.FetchConnectionsAt(context.Background(), from, to, via, date, timeStr, arrival)
-		return ConnectionsMsg{cr, err}
+		cr, err := c.FetchConnectionsWith(ctx, from, to, via, date, timeStr, opts)
+		return ConnectionsMsg{id, cr, err}
 	}
 }
@@ -29,44 +29,6 @@ func loadJSON(t *testing.T, path string, v interface{}) {
 	}
 }
 
-func TestMakeHTTPRequest(t *testing.T) {
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("Accept-Encoding") == "" || r.Header.Get("User-Agent") == "" {
-			t.Errorf("missing headers")
-		}
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"ok":true}`))
-	}
-	server := httptest.NewServer(http.HandlerFunc(handler))
-	defer server.Close()
-
-	u, _ := url.Parse(server.URL)
-	client := NewClient(&http.Client{Transport: rewriteTransport{u}})
-
-	body, err := client.makeHTTPRequest(context.Background(), baseURL+"/test")
-	if err != nil {
-		t.Fatalf("makeHTTPRequest error: %v", err)
-	}
-	if string(body) != "{\"ok\":true}" {
-		t.Errorf("unexpected body: %s", string(body))
-	}
-}
-
-func TestMakeHTTPRequestStatus(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
-	}))
-	defer server.Close()
-
-	u, _ := url.Parse(server.URL)
-	client := NewClient(&http.Client{Transport: rewriteTransport{u}})
-
-	_, err := client.makeHTTPRequest(context.Background(), baseURL+"/fail")
-	if err == nil {
-		t.Fatal("expected error for non-200 status")
-	}
-}
-
 func TestValidateStationCaching(t *testing.T) {
 	calls := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -146,6 +108,30 @@ func TestFetchStationboardAt(t *testing.T) {
 	}
 }
 
+func TestFetchStationboardArrivals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") != "arrival" {
+			t.Errorf("expected arrival board, got type %q", r.URL.Query().Get("type"))
+		}
+		http.ServeFile(w, r, filepath.Join("..", "..", "testdata", "stationboard.json"))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	c := NewClient(&http.Client{Transport: rewriteTransport{u}})
+
+	sb, err := c.FetchStationboardWith(context.Background(), "Chur", "", "", StationboardOptions{Arrivals: true})
+	if err != nil || sb == nil {
+		t.Fatalf("FetchStationboardWith error: %v", err)
+	}
+	if !sb.Arrivals {
+		t.Errorf("expected board to be marked as arrivals")
+	}
+	if got := sb.Stationboard[0].Origin(); got != "Chur" {
+		t.Errorf("unexpected origin %q", got)
+	}
+}
+
 func TestFetchConnections(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, filepath.Join("..", "..", "testdata", "connections.json"))
@@ -240,3 +226,35 @@ func TestMin(t *testing.T) {
 		t.Errorf("min failed")
 	}
 }
+
+func TestSearchStationsCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join("..", "..", "testdata", "locations.json"))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	c := NewClient(&http.Client{Transport: rewriteTransport{u}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.SearchStations(ctx, "Chur"); err == nil {
+		t.Fatal("expected error for canceled context")
+	}
+	// a canceled lookup must not poison the cache
+	stations, err := c.SearchStations(context.Background(), "Chur")
+	if err != nil || len(stations) != 5 {
+		t.Fatalf("SearchStations failed: %v", err)
+	}
+}
+
+func TestSearchStationsCmd(t *testing.T) {
+	c := NewClientWithProvider(NewFixtureProvider(filepath.Join("..", "..", "testdata")))
+	msg, ok := c.SearchStationsCmd(context.Background(), "Chur")().(LocationsMsg)
+	if !ok {
+		t.Fatalf("expected LocationsMsg")
+	}
+	if msg.Err != nil || msg.Query != "Chur" || len(msg.Stations) == 0 {
+		t.Errorf("unexpected result %+v", msg)
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchStationboards fetches the boards of several stations concurrently and
+// merges them into one board sorted by time. The merged board is named after
+// all stations, lists them in Stations and keeps the station of every entry
+// in its Stop. A single station is fetched as by FetchStationboardWith.
+//
+// Each station returns its own page of departures, so the merged board ends
+// where the first full page ends: later departures of the other stations
+// would be missing.
+func (c *Client) FetchStationboards(ctx context.Context, stations []string, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	switch len(stations) {
+	case 0:
+		return nil, fmt.Errorf("no station given")
+	case 1:
+		return c.FetchStationboardWith(ctx, stations[0], date, timeStr, opts)
+	}
+
+	boards := make([]*StationboardResponse, len(stations))
+	errs := make([]error, len(stations))
+	var wg sync.WaitGroup
+	for i, station := range stations {
+		wg.Add(1)
+		go func(i int, station string) {
+			defer wg.Done()
+			boards[i], errs[i] = c.FetchStationboardWith(ctx, station, date, timeStr, opts)
+		}(i, station)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", stations[i], err)
+		}
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultStationboardLimit
+	}
+	return mergeStationboards(boards, limit), nil
+}
+
+// FetchStationboardsCmd is FetchStationboards as a tea.Cmd; empty date and
+// timeStr request the current boards.
+func (c *Client) FetchStationboardsCmd(ctx context.Context, id int, stations []string, date, timeStr string, opts StationboardOptions) tea.Cmd {
+	return func() tea.Msg {
+		sb, err := c.FetchStationboards(ctx, stations, date, timeStr, opts)
+		return StationboardMsg{id, sb, err}
+	}
+}
+
+// mergeStationboards combines boards fetched with the same options. Boards
+// that returned a full page of limit entries cut the merged board at their
+// last entry.
+func mergeStationboards(boards []*StationboardResponse, limit int) *StationboardResponse {
+	merged := &StationboardResponse{}
+	var names []string
+	var cutoff time.Time
+	type entry struct {
+		e StationboardEntry
+		t time.Time
+	}
+	var entries []entry
+	for _, sb := range boards {
+		if sb == nil {
+			continue
+		}
+		merged.Arrivals = sb.Arrivals
+		merged.Stations = append(merged.Stations, sb.Station)
+		names = append(names, sb.Station.Name)
+		if !sb.CachedAt.IsZero() && (merged.CachedAt.IsZero() || sb.CachedAt.Before(merged.CachedAt)) {
+			merged.CachedAt = sb.CachedAt
+		}
+		var last time.Time
+		for _, e := range sb.Stationboard {
+			e.Stop.Station = sb.Station
+			at := e.Stop.Departure
+			if sb.Arrivals {
+				at = e.Stop.Arrival
+			}
+			t, _ := ParseTime(at)
+			if t.After(last) {
+				last = t
+			}
+			entries = append(entries, entry{e, t})
+		}
+		if len(sb.Stationboard) >= limit && !last.IsZero() && (cutoff.IsZero() || last.Before(cutoff)) {
+			cutoff = last
+		}
+	}
+	merged.Station = Location{Name: strings.Join(names, " + ")}
+
+	// entries without a time go last, ties keep the order of the stations
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].t, entries[j].t
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	merged.Stationboard = []StationboardEntry{}
+	for _, e := range entries {
+		if !cutoff.IsZero() && (e.t.IsZero() || e.t.After(cutoff)) {
+			continue
+		}
+		merged.Stationboard = append(merged.Stationboard, e.e)
+	}
+	return merged
+}
+
+// EntryStation returns the station e stops at: its own station on a board
+// merged by FetchStationboards, the station of the board otherwise.
+func (sb *StationboardResponse) EntryStation(e StationboardEntry) Location {
+	if len(sb.Stations) > 1 && e.Stop.Station.Name != "" {
+		return e.Stop.Station
+	}
+	return sb.Station
+}
+
+// Combined reports whether the board merges the boards of several stations.
+func (sb *StationboardResponse) Combined() bool {
+	return sb != nil && len(sb.Stations) > 1
+}
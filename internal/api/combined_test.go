@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// boardsProvider serves a board per station, listing departures at the given
+// minutes past noon.
+type boardsProvider struct {
+	*FixtureProvider
+	boards map[string][]string
+}
+
+func (p *boardsProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	minutes, ok := p.boards[station]
+	if !ok {
+		return nil, errors.New("unknown station")
+	}
+	sb := &StationboardResponse{Station: Location{Name: station}}
+	for _, m := range minutes {
+		e := StationboardEntry{Category: "S", Number: m, To: "Thun"}
+		e.Stop.Departure = "2024-01-01T12:" + m + ":00+0100"
+		sb.Stationboard = append(sb.Stationboard, e)
+	}
+	return sb, nil
+}
+
+func TestFetchStationboards(t *testing.T) {
+	p := &boardsProvider{
+		FixtureProvider: NewFixtureProvider(filepath.Join("..", "..", "testdata")),
+		boards: map[string][]string{
+			"Bern":         {"02", "10", "20"},
+			"Wankdorf":     {"05", "10", "40"},
+			"Ostermundig.": {"50"},
+		},
+	}
+	c := NewClientWithProvider(p)
+
+	sb, err := c.FetchStationboards(context.Background(), []string{"Bern", "Wankdorf", "Ostermundig."}, "", "", StationboardOptions{Limit: 3})
+	if err != nil {
+		t.Fatalf("FetchStationboards: %v", err)
+	}
+	if sb.Station.Name != "Bern + Wankdorf + Ostermundig." || !sb.Combined() {
+		t.Errorf("unexpected combined station %q", sb.Station.Name)
+	}
+	// both full boards end by 12:20, later departures of Wankdorf are cut
+	var got []string
+	for _, e := range sb.Stationboard {
+		got = append(got, e.Number+"@"+sb.EntryStation(e).Name)
+	}
+	if want := "02@Bern 05@Wankdorf 10@Bern 10@Wankdorf 20@Bern"; strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+
+	single, err := c.FetchStationboards(context.Background(), []string{"Bern"}, "", "", StationboardOptions{})
+	if err != nil || single.Combined() || len(single.Stationboard) != 3 {
+		t.Errorf("a single station should be fetched as is, got %+v, %v", single, err)
+	}
+
+	if _, err := c.FetchStationboards(context.Background(), []string{"Bern", "Nowhere"}, "", "", StationboardOptions{}); err == nil || !strings.HasPrefix(err.Error(), "Nowhere:") {
+		t.Errorf("expected the failing station in the error, got %v", err)
+	}
+}
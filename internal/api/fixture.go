@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FixtureProvider serves recorded API responses from a directory containing
+// locations.json, stationboard.json and connections.json. The query
+// parameters are ignored, which makes it useful for tests and demos.
+type FixtureProvider struct {
+	dir string
+}
+
+// NewFixtureProvider creates a provider reading fixtures from dir.
+func NewFixtureProvider(dir string) *FixtureProvider {
+	return &FixtureProvider{dir: dir}
+}
+
+func (p *FixtureProvider) load(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to read fixture: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse fixture %s: %v", name, err)
+	}
+	return nil
+}
+
+func (p *FixtureProvider) Locations(ctx context.Context, query string) ([]Location, error) {
+	var result LocationResponse
+	if err := p.load("locations.json", &result); err != nil {
+		return nil, err
+	}
+	return result.Stations, nil
+}
+
+func (p *FixtureProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	var sb StationboardResponse
+	if err := p.load("stationboard.json", &sb); err != nil {
+		return nil, err
+	}
+	return &sb, nil
+}
+
+func (p *FixtureProvider) Connections(ctx context.Context, from, to string, via []string, date, timeStr string, opts ConnectionOptions) (*ConnectionsResponse, error) {
+	var cr ConnectionsResponse
+	if err := p.load("connections.json", &cr); err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixtureProvider(t *testing.T) {
+	c := NewClientWithProvider(NewFixtureProvider(filepath.Join("..", "..", "testdata")))
+
+	stations, err := c.ValidateStation("Chur")
+	if err != nil || len(stations) != 5 {
+		t.Fatalf("ValidateStation failed: %v", err)
+	}
+	sb, err := c.FetchStationboard(context.Background(), "Chur")
+	if err != nil || len(sb.Stationboard) != 1 {
+		t.Fatalf("FetchStationboard failed: %v", err)
+	}
+	cr, err := c.FetchConnectionsAt(context.Background(), "Chur", "Zürich Altstetten", nil, "2024-01-01", "12:00", false)
+	if err != nil || len(cr.Connections) != 1 {
+		t.Fatalf("FetchConnectionsAt failed: %v", err)
+	}
+}
+
+func TestFixtureProviderMissingDir(t *testing.T) {
+	p := NewFixtureProvider(t.TempDir())
+	if _, err := p.Stationboard(context.Background(), "Chur", "", "", StationboardOptions{}); err == nil {
+		t.Fatal("expected error for missing fixture")
+	}
+}
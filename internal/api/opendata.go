@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the root of the public transport.opendata.ch API.
+	DefaultBaseURL = "https://transport.opendata.ch/v1"
+	// DefaultUserAgent is sent with every request unless overridden.
+	DefaultUserAgent = "SwissTransportTUI/1.0"
+)
+
+const (
+	endpointStation = "/stationboard"
+	endpointConn    = "/connections"
+	endpointLoc     = "/locations"
+)
+
+// OpenDataProvider talks to the transport.opendata.ch API or any server
+// exposing the same JSON interface.
+type OpenDataProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+}
+
+// NewOpenDataProvider creates a provider for the given base URL. A nil
+// httpClient uses a client with an 8 second timeout, empty baseURL and
+// userAgent fall back to DefaultBaseURL and DefaultUserAgent.
+func NewOpenDataProvider(httpClient *http.Client, baseURL, userAgent string) *OpenDataProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 8 * time.Second}
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &OpenDataProvider{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		userAgent:  userAgent,
+	}
+}
+
+func (p *OpenDataProvider) makeHTTPRequest(ctx context.Context, requestURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	req.Header.Set("User-Agent", p.userAgent)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if len(body) == 0 {
+		return nil, fmt.Errorf("received empty response from API")
+	}
+
+	trimmed := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, fmt.Errorf("received non-JSON response: %s", string(body)[:min(100, len(body))])
+	}
+
+	return body, nil
+}
+
+func (p *OpenDataProvider) Locations(ctx context.Context, query string) ([]Location, error) {
+	encodedQuery := url.QueryEscape(strings.TrimSpace(query))
+	requestURL := fmt.Sprintf("%s%s?query=%s&type=station", p.baseURL, endpointLoc, encodedQuery)
+
+	body, err := p.makeHTTPRequest(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var result LocationResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %v", err)
+	}
+	return result.Stations, nil
+}
+
+func (p *OpenDataProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultStationboardLimit
+	}
+	encoded := url.QueryEscape(strings.TrimSpace(station))
+	requestURL := fmt.Sprintf("%s%s?station=%s&limit=%d", p.baseURL, endpointStation, encoded, limit)
+	if opts.Arrivals {
+		requestURL += "&type=arrival"
+	}
+	requestURL += transportationsQuery(opts.Transportations)
+	if date != "" && timeStr != "" {
+		dt := url.QueryEscape(fmt.Sprintf("%s %s", date, timeStr))
+		requestURL += "&datetime=" + dt
+	}
+
+	body, err := p.makeHTTPRequest(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb StationboardResponse
+	if err := json.Unmarshal(body, &sb); err != nil {
+		return nil, fmt.Errorf("failed to parse stationboard JSON: %v", err)
+	}
+	return &sb, nil
+}
+
+func (p *OpenDataProvider) Connections(ctx context.Context, from, to string, via []string, date, timeStr string, opts ConnectionOptions) (*ConnectionsResponse, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultConnectionLimit
+	}
+	encodedFrom := url.QueryEscape(strings.TrimSpace(from))
+	encodedTo := url.QueryEscape(strings.TrimSpace(to))
+	requestURL := fmt.Sprintf("%s%s?from=%s&to=%s&limit=%d", p.baseURL, endpointConn, encodedFrom, encodedTo, limit)
+	for _, v := range via {
+		requestURL += "&via[]=" + url.QueryEscape(strings.TrimSpace(v))
+	}
+	if date != "" {
+		requestURL += "&date=" + url.QueryEscape(date)
+	}
+	if timeStr != "" {
+		requestURL += "&time=" + url.QueryEscape(timeStr)
+	}
+	if opts.Arrival {
+		requestURL += "&isArrivalTime=1"
+	}
+	requestURL += transportationsQuery(opts.Transportations)
+	requestURL += searchOptionsQuery(opts)
+
+	body, err := p.makeHTTPRequest(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var cr ConnectionsResponse
+	if err := json.Unmarshal(body, &cr); err != nil {
+		return nil, fmt.Errorf("failed to parse connections JSON: %v", err)
+	}
+	return &cr, nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestMakeHTTPRequest(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") == "" || r.Header.Get("User-Agent") == "" {
+			t.Errorf("missing headers")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	p := NewOpenDataProvider(&http.Client{Transport: rewriteTransport{u}}, "", "")
+
+	body, err := p.makeHTTPRequest(context.Background(), DefaultBaseURL+"/test")
+	if err != nil {
+		t.Fatalf("makeHTTPRequest error: %v", err)
+	}
+	if string(body) != "{\"ok\":true}" {
+		t.Errorf("unexpected body: %s", string(body))
+	}
+}
+
+func TestMakeHTTPRequestStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	p := NewOpenDataProvider(&http.Client{Transport: rewriteTransport{u}}, "", "")
+
+	_, err := p.makeHTTPRequest(context.Background(), DefaultBaseURL+"/fail")
+	if err == nil {
+		t.Fatal("expected error for non-200 status")
+	}
+}
+
+func TestOpenDataProviderBaseURL(t *testing.T) {
+	var gotPath, gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"stations":[{"id":"1","name":"Chur"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenDataProvider(nil, server.URL+"/v1/", "test-agent")
+	stations, err := p.Locations(context.Background(), "Chur")
+	if err != nil || len(stations) != 1 {
+		t.Fatalf("Locations error: %v", err)
+	}
+	if gotPath != "/v1/locations" {
+		t.Errorf("unexpected path %s", gotPath)
+	}
+	if gotAgent != "test-agent" {
+		t.Errorf("unexpected user agent %s", gotAgent)
+	}
+}
+
+func TestOpenDataStationboardLimit(t *testing.T) {
+	var limits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		w.Write([]byte(`{"station":{"name":"Chur"},"stationboard":[]}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenDataProvider(nil, server.URL, "")
+	for _, opts := range []StationboardOptions{{}, {Limit: 30}} {
+		if _, err := p.Stationboard(context.Background(), "Chur", "", "", opts); err != nil {
+			t.Fatalf("Stationboard error: %v", err)
+		}
+	}
+	if len(limits) != 2 || limits[0] != "10" || limits[1] != "30" {
+		t.Errorf("unexpected limits %v", limits)
+	}
+}
+
+func TestOpenDataTransportations(t *testing.T) {
+	var got [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query()["transportations[]"])
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenDataProvider(nil, server.URL, "")
+	types := []string{"train", "bus"}
+	if _, err := p.Stationboard(context.Background(), "Chur", "", "", StationboardOptions{Transportations: types}); err != nil {
+		t.Fatalf("Stationboard error: %v", err)
+	}
+	if _, err := p.Connections(context.Background(), "Chur", "Bern", nil, "", "", ConnectionOptions{Transportations: types}); err != nil {
+		t.Fatalf("Connections error: %v", err)
+	}
+	for _, q := range got {
+		if len(q) != 2 || q[0] != "train" || q[1] != "bus" {
+			t.Errorf("unexpected transportations %v", q)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(got))
+	}
+}
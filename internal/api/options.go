@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccessibilityLevels lists the values of ConnectionOptions.Accessibility:
+// boarding without help, with help by the staff, and with help booked in
+// advance.
+var AccessibilityLevels = []string{"independent_boarding", "assisted_boarding", "advanced_notice"}
+
+// ParseAccessibility validates an accessibility level. The empty string
+// means no requirement.
+func ParseAccessibility(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+	for _, l := range AccessibilityLevels {
+		if s == l {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown accessibility %q, want one of %s", s, strings.Join(AccessibilityLevels, ", "))
+}
+
+// searchOptionsQuery encodes the restricting and paging connection options
+// as query parameters.
+func searchOptionsQuery(opts ConnectionOptions) string {
+	var q strings.Builder
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"direct", opts.Direct},
+		{"sleeper", opts.Sleeper},
+		{"couchette", opts.Couchette},
+		{"bike", opts.Bike},
+	} {
+		if f.set {
+			q.WriteString("&" + f.name + "=1")
+		}
+	}
+	if opts.Accessibility != "" {
+		q.WriteString("&accessibility=" + opts.Accessibility)
+	}
+	if opts.Page != 0 {
+		q.WriteString(fmt.Sprintf("&page=%d", opts.Page))
+	}
+	return q.String()
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseAccessibility(t *testing.T) {
+	if got, err := ParseAccessibility(" Assisted_Boarding"); err != nil || got != "assisted_boarding" {
+		t.Errorf("unexpected level %q (%v)", got, err)
+	}
+	if got, err := ParseAccessibility(""); err != nil || got != "" {
+		t.Errorf("empty level should mean no requirement, got %q (%v)", got, err)
+	}
+	if _, err := ParseAccessibility("ramp"); err == nil {
+		t.Errorf("expected error for unknown level")
+	}
+}
+
+func TestOpenDataConnectionOptions(t *testing.T) {
+	var got []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query())
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenDataProvider(nil, server.URL, "")
+	ctx := context.Background()
+	if _, err := p.Connections(ctx, "Chur", "Bern", nil, "", "", ConnectionOptions{}); err != nil {
+		t.Fatalf("Connections error: %v", err)
+	}
+	opts := ConnectionOptions{Direct: true, Couchette: true, Bike: true, Accessibility: "advanced_notice", Limit: 8, Page: 2}
+	if _, err := p.Connections(ctx, "Chur", "Bern", nil, "", "", opts); err != nil {
+		t.Fatalf("Connections error: %v", err)
+	}
+
+	plain := got[0]
+	if plain.Get("limit") != "5" || plain.Has("direct") || plain.Has("page") || plain.Has("accessibility") {
+		t.Errorf("unexpected default query %v", plain)
+	}
+	q := got[1]
+	for key, want := range map[string]string{
+		"direct":        "1",
+		"couchette":     "1",
+		"bike":          "1",
+		"accessibility": "advanced_notice",
+		"limit":         "8",
+		"page":          "2",
+	} {
+		if q.Get(key) != want {
+			t.Errorf("expected %s=%s, got %q", key, want, q.Get(key))
+		}
+	}
+	if q.Has("sleeper") {
+		t.Errorf("sleeper was not requested")
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import "context"
+
+// DefaultStationboardLimit is the number of departures requested when
+// StationboardOptions.Limit is not set.
+const DefaultStationboardLimit = 10
+
+// StationboardOptions refine a stationboard request. The zero value selects
+// the defaults.
+type StationboardOptions struct {
+	// Limit is the maximum number of departures, DefaultStationboardLimit if 0.
+	Limit int
+	// Arrivals requests the arriving instead of the departing trains.
+	Arrivals bool
+	// Transportations restricts the board to the given transport types, see
+	// Transportations. Empty means all types.
+	Transportations []string
+}
+
+const (
+	// DefaultConnectionLimit is the number of connections requested when
+	// ConnectionOptions.Limit is not set.
+	DefaultConnectionLimit = 5
+	// MaxConnectionLimit is the largest ConnectionOptions.Limit the API
+	// accepts.
+	MaxConnectionLimit = 16
+)
+
+// ConnectionOptions refine a connection search. The zero value searches
+// departures with the default number of results.
+type ConnectionOptions struct {
+	// Arrival treats the date and time of the search as the arrival time.
+	Arrival bool
+	// Transportations restricts the connections to the given transport types,
+	// see Transportations. Empty means all types.
+	Transportations []string
+	// Direct only returns connections without changes.
+	Direct bool
+	// Sleeper and Couchette only return night trains with sleeping or
+	// couchette cars.
+	Sleeper   bool
+	Couchette bool
+	// Bike only returns connections allowing bicycles.
+	Bike bool
+	// Accessibility requires step-free boarding, see AccessibilityLevels.
+	// Empty means no requirement.
+	Accessibility string
+	// Limit is the number of connections, DefaultConnectionLimit if 0.
+	Limit int
+	// Page selects further results of the same search: 0 is the first page,
+	// 1 the following connections. It must not be negative; earlier
+	// connections are found by searching an earlier time.
+	Page int
+}
+
+// Provider is a timetable backend. The opendata.ch API is the default
+// implementation; alternative backends (a local mock server, recorded
+// fixtures, ...) can be plugged into a Client via NewClientWithProvider.
+type Provider interface {
+	// Locations returns the stations matching the given query.
+	Locations(ctx context.Context, query string) ([]Location, error)
+	// Stationboard returns the departures of a station. Empty date and
+	// timeStr request the current board.
+	Stationboard(ctx context.Context, station, date, timeStr string, opts StationboardOptions) (*StationboardResponse, error)
+	// Connections returns connections between from and to, optionally via
+	// other stations. Empty date and timeStr search from now on.
+	Connections(ctx context.Context, from, to string, via []string, date, timeStr string, opts ConnectionOptions) (*ConnectionsResponse, error)
+}
@@ -0,0 +1,22 @@
+package api
+
+import "time"
+
+// ParseTime parses timestamps returned by the API. It supports both the
+// standard RFC3339 format (with timezone colon) and the variant without a
+// colon in the timezone offset (e.g. "+0200").
+func ParseTime(v string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05-0700",
+	}
+	var lastErr error
+	for _, l := range layouts {
+		if t, err := time.Parse(l, v); err == nil {
+			return t, nil
+		} else {
+			lastErr = err
+		}
+	}
+	return time.Time{}, lastErr
+}
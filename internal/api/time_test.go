@@ -0,0 +1,20 @@
+package api
+
+import "testing"
+
+func TestParseTime(t *testing.T) {
+	a, err := ParseTime("2024-01-01T12:30:00+01:00")
+	if err != nil {
+		t.Fatalf("RFC3339 parse failed: %v", err)
+	}
+	b, err := ParseTime("2024-01-01T12:30:00+0100")
+	if err != nil {
+		t.Fatalf("offset without colon failed: %v", err)
+	}
+	if !a.Equal(b) {
+		t.Errorf("expected equal times, got %v and %v", a, b)
+	}
+	if _, err := ParseTime("12:30"); err == nil {
+		t.Error("expected error for time without date")
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transportations lists the transport types accepted by the
+// transportations[] filter of stationboard and connection requests.
+var Transportations = []string{"train", "tram", "ship", "bus", "cableway"}
+
+// ParseTransportations splits a comma-separated list of transport types such
+// as "train,bus". Unknown types are rejected, duplicates dropped and an empty
+// list means no filter.
+func ParseTransportations(s string) ([]string, error) {
+	var types []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if !validTransportation(t) {
+			return nil, fmt.Errorf("unknown transport type %q, want one of %s", t, strings.Join(Transportations, ", "))
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+func validTransportation(t string) bool {
+	for _, v := range Transportations {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// transportationsQuery encodes a transport filter as query parameters.
+func transportationsQuery(types []string) string {
+	var q strings.Builder
+	for _, t := range types {
+		q.WriteString("&transportations[]=" + t)
+	}
+	return q.String()
+}
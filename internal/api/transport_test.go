@@ -0,0 +1,19 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTransportations(t *testing.T) {
+	got, err := ParseTransportations(" Train, bus,train,")
+	if err != nil || !reflect.DeepEqual(got, []string{"train", "bus"}) {
+		t.Errorf("unexpected types %v (%v)", got, err)
+	}
+	if got, err := ParseTransportations(""); err != nil || got != nil {
+		t.Errorf("empty list should mean no filter, got %v (%v)", got, err)
+	}
+	if _, err := ParseTransportations("train,rocket"); err == nil {
+		t.Errorf("expected error for unknown type")
+	}
+}
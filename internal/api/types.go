@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // JSON data models for the Swiss transport API
 
 type Coordinate struct {
@@ -19,8 +21,15 @@ type LocationResponse struct {
 	Stations []Location `json:"stations"`
 }
 
+// Prognosis holds the real-time forecast for a stop. Empty fields mean no
+// deviation from the timetable is known. Capacities range from 1 (low) to 3
+// (high) occupancy, 0 if unknown.
 type Prognosis struct {
-	Platform string `json:"platform"`
+	Platform    string `json:"platform"`
+	Departure   string `json:"departure"`
+	Arrival     string `json:"arrival"`
+	Capacity1st int    `json:"capacity1st"`
+	Capacity2nd int    `json:"capacity2nd"`
 }
 
 type Stop struct {
@@ -30,6 +39,7 @@ type Stop struct {
 	Delay     int       `json:"delay"`
 	Platform  string    `json:"platform"`
 	Prognosis Prognosis `json:"prognosis"`
+	Cancelled bool      `json:"cancelled"`
 }
 
 type StationboardEntry struct {
@@ -37,12 +47,34 @@ type StationboardEntry struct {
 	Name     string `json:"name"`
 	Category string `json:"category"`
 	Number   string `json:"number"`
+	Operator string `json:"operator"`
 	To       string `json:"to"`
+	PassList []Stop `json:"passList"`
+}
+
+// Origin returns the station an arriving train started from, the first stop
+// of its pass list, or "" if unknown.
+func (e StationboardEntry) Origin() string {
+	if len(e.PassList) == 0 {
+		return ""
+	}
+	return e.PassList[0].Station.Name
 }
 
 type StationboardResponse struct {
 	Station      Location            `json:"station"`
 	Stationboard []StationboardEntry `json:"stationboard"`
+
+	// Arrivals is set when the board lists arriving instead of departing
+	// trains.
+	Arrivals bool `json:"-"`
+
+	// Stations lists the stations of a board merged by FetchStationboards.
+	Stations []Location `json:"-"`
+
+	// CachedAt is set when the response is an outdated copy served from the
+	// offline cache because the backend could not be reached.
+	CachedAt time.Time `json:"-"`
 }
 
 type Journey struct {
@@ -51,6 +83,13 @@ type Journey struct {
 	Number   string `json:"number"`
 	Operator string `json:"operator"`
 	To       string `json:"to"`
+	// Capacity1st and Capacity2nd forecast the occupancy of the journey in
+	// the respective class, see Prognosis.
+	Capacity1st int `json:"capacity1st"`
+	Capacity2nd int `json:"capacity2nd"`
+	// PassList holds every stop of the journey within the section, including
+	// its departure and arrival stops.
+	PassList []Stop `json:"passList"`
 }
 
 type Walk struct {
@@ -66,19 +105,29 @@ type Section struct {
 
 type Connection struct {
 	From struct {
-		Station   Location `json:"station"`
-		Departure string   `json:"departure"`
-		Delay     int      `json:"delay"`
+		Station   Location  `json:"station"`
+		Departure string    `json:"departure"`
+		Delay     int       `json:"delay"`
+		Platform  string    `json:"platform"`
+		Prognosis Prognosis `json:"prognosis"`
 	} `json:"from"`
 	To struct {
-		Station Location `json:"station"`
-		Arrival string   `json:"arrival"`
-		Delay   int      `json:"delay"`
+		Station   Location  `json:"station"`
+		Arrival   string    `json:"arrival"`
+		Delay     int       `json:"delay"`
+		Platform  string    `json:"platform"`
+		Prognosis Prognosis `json:"prognosis"`
 	} `json:"to"`
-	Duration string    `json:"duration"`
-	Sections []Section `json:"sections"`
+	Duration    string    `json:"duration"`
+	Capacity1st int       `json:"capacity1st"`
+	Capacity2nd int       `json:"capacity2nd"`
+	Sections    []Section `json:"sections"`
 }
 
 type ConnectionsResponse struct {
 	Connections []Connection `json:"connections"`
+
+	// CachedAt is set when the response is an outdated copy served from the
+	// offline cache because the backend could not be reached.
+	CachedAt time.Time `json:"-"`
 }
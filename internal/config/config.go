@@ -0,0 +1,337 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+)
+
+// Supported values for Config.Provider.
+const (
+	ProviderOpenData = "opendata"
+	ProviderFixtures = "fixtures"
+)
+
+// envPrefix is prepended to the upper-cased config keys (dots replaced by
+// underscores) to form environment overrides, e.g. SBBUDDY_HTTP_TIMEOUT.
+const envPrefix = "SBBUDDY_"
+
+// Config holds the user configurable settings of SBBuddy.
+type Config struct {
+	Provider    string
+	FixturesDir string
+	BaseURL     string
+	UserAgent   string
+	DataDir     string
+	TravelClass int
+
+	StationboardLimit int           // departures per stationboard page
+	ConnectionLimit   int           // connections per search page
+	LiveInterval      time.Duration // refresh interval of live stationboards
+
+	Timeout             time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	CacheEnabled bool
+	CacheDir     string
+	LocationsTTL time.Duration
+	TimetableTTL time.Duration
+	MaxStale     time.Duration
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		Provider:            ProviderOpenData,
+		BaseURL:             api.DefaultBaseURL,
+		UserAgent:           api.DefaultUserAgent,
+		TravelClass:         2,
+		StationboardLimit:   api.DefaultStationboardLimit,
+		ConnectionLimit:     api.DefaultConnectionLimit,
+		LiveInterval:        30 * time.Second,
+		Timeout:             8 * time.Second,
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     30 * time.Second,
+		CacheEnabled:        true,
+		LocationsTTL:        30 * 24 * time.Hour,
+		TimetableTTL:        time.Minute,
+		MaxStale:            7 * 24 * time.Hour,
+	}
+}
+
+// setters maps config keys to functions applying a raw value.
+var setters = map[string]func(c *Config, v string) error{
+	"provider":                     func(c *Config, v string) error { c.Provider = v; return nil },
+	"fixtures_dir":                 func(c *Config, v string) error { c.FixturesDir = v; return nil },
+	"base_url":                     func(c *Config, v string) error { c.BaseURL = v; return nil },
+	"user_agent":                   func(c *Config, v string) error { c.UserAgent = v; return nil },
+	"data_dir":                     func(c *Config, v string) error { c.DataDir = v; return nil },
+	"travel_class":                 func(c *Config, v string) error { return setClass(&c.TravelClass, v) },
+	"stationboard_limit":           func(c *Config, v string) error { return setLimit(&c.StationboardLimit, v, 100) },
+	"connection_limit":             func(c *Config, v string) error { return setLimit(&c.ConnectionLimit, v, api.MaxConnectionLimit) },
+	"live_interval":                func(c *Config, v string) error { return setInterval(&c.LiveInterval, v) },
+	"http.timeout":                 func(c *Config, v string) error { return setDuration(&c.Timeout, v) },
+	"http.max_idle_conns":          func(c *Config, v string) error { return setInt(&c.MaxIdleConns, v) },
+	"http.max_idle_conns_per_host": func(c *Config, v string) error { return setInt(&c.MaxIdleConnsPerHost, v) },
+	"http.idle_conn_timeout":       func(c *Config, v string) error { return setDuration(&c.IdleConnTimeout, v) },
+	"cache.enabled":                func(c *Config, v string) error { return setBool(&c.CacheEnabled, v) },
+	"cache.dir":                    func(c *Config, v string) error { c.CacheDir = v; return nil },
+	"cache.locations_ttl":          func(c *Config, v string) error { return setDuration(&c.LocationsTTL, v) },
+	"cache.timetable_ttl":          func(c *Config, v string) error { return setDuration(&c.TimetableTTL, v) },
+	"cache.max_stale":              func(c *Config, v string) error { return setDuration(&c.MaxStale, v) },
+}
+
+func setInt(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", v)
+	}
+	*dst = b
+	return nil
+}
+
+// setLimit accepts result counts between 1 and max.
+func setLimit(dst *int, v string, max int) error {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > max {
+		return fmt.Errorf("invalid limit %q, want 1 to %d", v, max)
+	}
+	*dst = n
+	return nil
+}
+
+// setClass accepts the travel classes 1 and 2.
+func setClass(dst *int, v string) error {
+	if v != "1" && v != "2" {
+		return fmt.Errorf("invalid travel class %q, want 1 or 2", v)
+	}
+	*dst = int(v[0] - '0')
+	return nil
+}
+
+// setInterval accepts refresh intervals of at least ten seconds.
+func setInterval(dst *time.Duration, v string) error {
+	var d time.Duration
+	if err := setDuration(&d, v); err != nil {
+		return err
+	}
+	if d < 10*time.Second {
+		return fmt.Errorf("invalid interval %q, want at least 10s", v)
+	}
+	*dst = d
+	return nil
+}
+
+// setDuration accepts Go duration strings ("8s", "1m30s") or plain seconds.
+func setDuration(dst *time.Duration, v string) error {
+	if n, err := strconv.Atoi(v); err == nil {
+		*dst = time.Duration(n) * time.Second
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", v)
+	}
+	*dst = d
+	return nil
+}
+
+func (c *Config) set(key, value string) error {
+	setter, ok := setters[key]
+	if !ok {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	if err := setter(c, value); err != nil {
+		return fmt.Errorf("%s: %v", key, err)
+	}
+	return nil
+}
+
+// Dir returns the SBBuddy configuration directory, usually
+// ~/.config/sbbuddy on Linux.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "sbbuddy"), nil
+}
+
+// DataDirectory returns the directory holding user data such as favorites:
+// data_dir if set, otherwise $XDG_DATA_HOME/sbbuddy or ~/.local/share/sbbuddy
+// on Unix and the config directory elsewhere.
+func (c Config) DataDirectory() (string, error) {
+	if c.DataDir != "" {
+		return c.DataDir, nil
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "sbbuddy"), nil
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", "sbbuddy"), nil
+	}
+	return Dir()
+}
+
+// CacheDirectory returns the directory of the response cache, usually
+// ~/.cache/sbbuddy on Linux, unless overridden by cache.dir.
+func (c Config) CacheDirectory() (string, error) {
+	if c.CacheDir != "" {
+		return c.CacheDir, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "sbbuddy"), nil
+}
+
+// Path returns the location of the config file. SBBUDDY_CONFIG overrides the
+// default config.toml inside Dir.
+func Path() (string, error) {
+	if p := os.Getenv(envPrefix + "CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load returns the default configuration overlaid with the config file (if it
+// exists) and SBBUDDY_* environment variables, in that order.
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err == nil {
+		if err := cfg.loadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open config: %v", err)
+	}
+	defer f.Close()
+
+	values, err := parseTOML(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := c.set(k, values[k]); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	for key := range setters {
+		name := EnvName(key)
+		if v, ok := lookup(name); ok && v != "" {
+			if err := c.set(key, v); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EnvName returns the environment variable overriding the given config key.
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// HTTPClient builds an HTTP client with connection pooling from the settings.
+func (c Config) HTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: c.Timeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConns:        c.MaxIdleConns,
+			MaxIdleConnsPerHost: c.MaxIdleConnsPerHost,
+			IdleConnTimeout:     c.IdleConnTimeout,
+		},
+	}
+}
+
+// NewProvider creates the timetable backend selected by the configuration,
+// wrapped in the persistent response cache when it is enabled.
+func (c Config) NewProvider() (api.Provider, error) {
+	var p api.Provider
+	switch c.Provider {
+	case "", ProviderOpenData:
+		p = api.NewOpenDataProvider(c.HTTPClient(), c.BaseURL, c.UserAgent)
+	case ProviderFixtures:
+		if c.FixturesDir == "" {
+			return nil, fmt.Errorf("provider %q requires fixtures_dir", ProviderFixtures)
+		}
+		// Recorded fixtures are already offline, caching them is pointless.
+		return api.NewFixtureProvider(c.FixturesDir), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", c.Provider)
+	}
+	if !c.CacheEnabled {
+		return p, nil
+	}
+	dir, err := c.CacheDirectory()
+	if err != nil {
+		// Without a cache directory we simply run uncached.
+		return p, nil
+	}
+	cp := api.NewCachedProvider(p, dir, c.LocationsTTL, c.TimetableTTL, c.MaxStale)
+	cp.Prune()
+	return cp, nil
+}
+
+// NewClient creates an API client for the configured backend.
+func (c Config) NewClient() (*api.Client, error) {
+	p, err := c.NewProvider()
+	if err != nil {
+		return nil, err
+	}
+	return api.NewClientWithProvider(p), nil
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, `
+# point at the caching proxy
+base_url = "http://proxy.local/v1"
+user_agent = "Team/2.0"
+
+[http]
+timeout = "3s"
+max_idle_conns = 20
+idle_conn_timeout = 60
+`)
+	cfg := Default()
+	if err := cfg.loadFile(path); err != nil {
+		t.Fatalf("loadFile error: %v", err)
+	}
+	if cfg.BaseURL != "http://proxy.local/v1" || cfg.UserAgent != "Team/2.0" {
+		t.Errorf("unexpected strings: %+v", cfg)
+	}
+	if cfg.Timeout != 3*time.Second || cfg.IdleConnTimeout != time.Minute {
+		t.Errorf("unexpected durations: %v %v", cfg.Timeout, cfg.IdleConnTimeout)
+	}
+	if cfg.MaxIdleConns != 20 || cfg.MaxIdleConnsPerHost != 5 {
+		t.Errorf("unexpected pool settings: %d %d", cfg.MaxIdleConns, cfg.MaxIdleConnsPerHost)
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg := Default()
+	if err := cfg.loadFile(filepath.Join(t.TempDir(), "missing.toml")); err != nil {
+		t.Fatalf("missing file should be ignored: %v", err)
+	}
+}
+
+func TestLoadFileUnknownKey(t *testing.T) {
+	path := writeConfig(t, "bogus = 1\n")
+	cfg := Default()
+	if err := cfg.loadFile(path); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	env := map[string]string{
+		"SBBUDDY_BASE_URL":     "http://localhost:8080",
+		"SBBUDDY_HTTP_TIMEOUT": "2s",
+	}
+	cfg := Default()
+	err := cfg.loadEnv(func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	})
+	if err != nil {
+		t.Fatalf("loadEnv error: %v", err)
+	}
+	if cfg.BaseURL != "http://localhost:8080" || cfg.Timeout != 2*time.Second {
+		t.Errorf("env not applied: %+v", cfg)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, `base_url = "http://file/v1"`)
+	t.Setenv("SBBUDDY_CONFIG", path)
+	t.Setenv("SBBUDDY_BASE_URL", "http://env/v1")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.BaseURL != "http://env/v1" {
+		t.Errorf("expected env override, got %s", cfg.BaseURL)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	cfg := Default()
+	cfg.CacheDir = t.TempDir()
+	p, err := cfg.NewProvider()
+	if err != nil {
+		t.Fatalf("default provider: %v", err)
+	}
+	if _, ok := p.(*api.CachedProvider); !ok {
+		t.Errorf("expected cached provider, got %T", p)
+	}
+	cfg.CacheEnabled = false
+	if p, _ := cfg.NewProvider(); p == nil {
+		t.Fatal("expected uncached provider")
+	} else if _, ok := p.(*api.OpenDataProvider); !ok {
+		t.Errorf("expected opendata provider, got %T", p)
+	}
+	cfg.Provider = ProviderFixtures
+	if _, err := cfg.NewProvider(); err == nil {
+		t.Error("expected error without fixtures_dir")
+	}
+	cfg.FixturesDir = filepath.Join("..", "..", "testdata")
+	if _, err := cfg.NewClient(); err != nil {
+		t.Errorf("fixtures provider: %v", err)
+	}
+	cfg.Provider = "nope"
+	if _, err := cfg.NewProvider(); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if n := EnvName("http.max_idle_conns"); n != "SBBUDDY_HTTP_MAX_IDLE_CONNS" {
+		t.Errorf("unexpected env name %s", n)
+	}
+}
+
+func TestDataDirectory(t *testing.T) {
+	cfg := Default()
+	cfg.DataDir = "/tmp/sbbuddy-data"
+	if dir, err := cfg.DataDirectory(); err != nil || dir != "/tmp/sbbuddy-data" {
+		t.Errorf("data_dir not honored: %s %v", dir, err)
+	}
+	cfg.DataDir = ""
+	t.Setenv("XDG_DATA_HOME", "/xdg")
+	if dir, err := cfg.DataDirectory(); err != nil || dir != filepath.Join("/xdg", "sbbuddy") {
+		t.Errorf("XDG_DATA_HOME not honored: %s %v", dir, err)
+	}
+}
+
+func TestTravelClass(t *testing.T) {
+	cfg := Default()
+	if cfg.TravelClass != 2 {
+		t.Errorf("default class = %d", cfg.TravelClass)
+	}
+	if err := cfg.set("travel_class", "1"); err != nil || cfg.TravelClass != 1 {
+		t.Errorf("travel_class not applied: %d %v", cfg.TravelClass, err)
+	}
+	if err := cfg.set("travel_class", "3"); err == nil {
+		t.Error("expected error for invalid class")
+	}
+}
+
+func TestStationboardLimit(t *testing.T) {
+	cfg := Default()
+	if cfg.StationboardLimit != api.DefaultStationboardLimit {
+		t.Errorf("default limit = %d", cfg.StationboardLimit)
+	}
+	if err := cfg.set("stationboard_limit", "25"); err != nil || cfg.StationboardLimit != 25 {
+		t.Errorf("stationboard_limit not applied: %d %v", cfg.StationboardLimit, err)
+	}
+	for _, v := range []string{"0", "-3", "many"} {
+		if err := cfg.set("stationboard_limit", v); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+func TestConnectionLimit(t *testing.T) {
+	cfg := Default()
+	if cfg.ConnectionLimit != api.DefaultConnectionLimit {
+		t.Errorf("default limit = %d", cfg.ConnectionLimit)
+	}
+	if err := cfg.set("connection_limit", "12"); err != nil || cfg.ConnectionLimit != 12 {
+		t.Errorf("connection_limit not applied: %d %v", cfg.ConnectionLimit, err)
+	}
+	for _, v := range []string{"0", "17"} {
+		if err := cfg.set("connection_limit", v); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+func TestLiveInterval(t *testing.T) {
+	cfg := Default()
+	if err := cfg.set("live_interval", "1m"); err != nil || cfg.LiveInterval != time.Minute {
+		t.Errorf("live_interval not applied: %v %v", cfg.LiveInterval, err)
+	}
+	if err := cfg.set("live_interval", "2s"); err == nil {
+		t.Errorf("expected error for too short interval")
+	}
+}
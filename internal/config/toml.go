@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseTOML reads the small TOML subset used by the config file: comments,
+// [table] headers and key = value pairs with string, integer or boolean
+// values. Keys inside a table are returned as "table.key".
+func parseTOML(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: malformed table header", lineNo)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		k = strings.TrimSpace(k)
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, `"`) {
+			unquoted, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string %s", lineNo, v)
+			}
+			v = unquoted
+		} else if strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'") && len(v) >= 2 {
+			v = v[1 : len(v)-1]
+		}
+		if section != "" {
+			k = section + "." + k
+		}
+		values[k] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// stripComment removes a trailing # comment that is not inside a string.
+func stripComment(line string) string {
+	inString := false
+	var quote rune
+	for i, r := range line {
+		switch {
+		case inString && r == quote:
+			inString = false
+		case !inString && (r == '"' || r == '\''):
+			inString = true
+			quote = r
+		case !inString && r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	input := `
+name = "Zürich # HB" # trailing comment
+raw = 'C:\path'
+count = 3
+
+[http]
+enabled = true
+`
+	values, err := parseTOML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseTOML error: %v", err)
+	}
+	want := map[string]string{
+		"name":         "Zürich # HB",
+		"raw":          `C:\path`,
+		"count":        "3",
+		"http.enabled": "true",
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, input := range []string{"[http", "novalue", `s = "unterminated`} {
+		if _, err := parseTOML(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	api "SBBuddy/internal/api"
+)
+
+// Supported output formats.
+const (
+	FormatTable  = "table"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatTSV    = "tsv"
+)
+
+// ValidFormat reports whether f is a known output format.
+func ValidFormat(f string) bool {
+	switch f {
+	case FormatTable, FormatJSON, FormatNDJSON, FormatCSV, FormatTSV:
+		return true
+	}
+	return false
+}
+
+var departureHeader = []string{"station", "departure", "delay", "category", "number", "line", "destination", "platform"}
+
+var arrivalHeader = []string{"station", "arrival", "delay", "category", "number", "line", "origin", "platform"}
+
+var connectionHeader = []string{"from", "to", "departure", "arrival", "departureDelay", "arrivalDelay", "durationMinutes", "changes", "sections"}
+
+// WriteStationboard writes the stationboard in the given machine-readable
+// format. JSON emits a single document, NDJSON one departure per line and
+// CSV/TSV one row per departure. CSV/TSV rows of arrival boards list the
+// arrival time and origin instead of departure time and destination.
+func WriteStationboard(w io.Writer, format string, sb *api.StationboardResponse) error {
+	board := NewStationboard(sb)
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, board)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, d := range board.Departures {
+			if err := enc.Encode(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV, FormatTSV:
+		var rows [][]string
+		for _, d := range board.Departures {
+			at, where := d.Departure, d.Destination
+			if board.Arrivals {
+				at, where = d.Arrival, d.Origin
+			}
+			rows = append(rows, []string{
+				d.Station, at, strconv.Itoa(d.Delay), d.Category,
+				d.Number, d.Line, where, d.Platform,
+			})
+		}
+		header := departureHeader
+		if board.Arrivals {
+			header = arrivalHeader
+		}
+		return writeDelimited(w, format, header, rows)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+// WriteConnections writes the connections in the given machine-readable
+// format. CSV/TSV rows summarize the sections as "line from-to" legs joined
+// by " | "; use JSON or NDJSON for the full section details.
+func WriteConnections(w io.Writer, format string, cr *api.ConnectionsResponse) error {
+	conns := NewConnections(cr)
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, conns)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, c := range conns {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV, FormatTSV:
+		var rows [][]string
+		for _, c := range conns {
+			rows = append(rows, []string{
+				c.From, c.To, c.Departure, c.Arrival,
+				strconv.Itoa(c.DepartureDelay), strconv.Itoa(c.ArrivalDelay),
+				strconv.Itoa(c.DurationMinutes), strconv.Itoa(c.Changes),
+				summarizeSections(c.Sections),
+			})
+		}
+		return writeDelimited(w, format, connectionHeader, rows)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+func summarizeSections(sections []Section) string {
+	var legs []string
+	for _, s := range sections {
+		label := s.Line
+		if s.Type == "walk" {
+			label = "walk"
+		}
+		legs = append(legs, fmt.Sprintf("%s %s-%s", label, s.From, s.To))
+	}
+	return strings.Join(legs, " | ")
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeDelimited(w io.Writer, format string, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if format == FormatTSV {
+		cw.Comma = '\t'
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	api "SBBuddy/internal/api"
+)
+
+func TestWriteStationboardFormats(t *testing.T) {
+	var sb api.StationboardResponse
+	loadFixture(t, "stationboard.json", &sb)
+
+	var buf bytes.Buffer
+	if err := WriteStationboard(&buf, FormatJSON, &sb); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var board Stationboard
+	if err := json.Unmarshal(buf.Bytes(), &board); err != nil || len(board.Departures) != 1 {
+		t.Errorf("json output not parseable: %v", err)
+	}
+
+	buf.Reset()
+	if err := WriteStationboard(&buf, FormatTSV, &sb); err != nil {
+		t.Fatalf("tsv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "station\tdeparture") {
+		t.Errorf("unexpected tsv output: %q", buf.String())
+	}
+}
+
+func TestWriteArrivalsCSV(t *testing.T) {
+	sb := api.StationboardResponse{
+		Station:  api.Location{Name: "Bern"},
+		Arrivals: true,
+		Stationboard: []api.StationboardEntry{{
+			Category: "IC",
+			Number:   "8",
+			To:       "Brig",
+			Stop:     api.Stop{Arrival: "2024-01-01T12:56:00+01:00", Platform: "6"},
+			PassList: []api.Stop{{Station: api.Location{Name: "Zürich HB"}}, {Station: api.Location{Name: "Bern"}}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStationboard(&buf, FormatCSV, &sb); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != "station,arrival,delay,category,number,line,origin,platform" {
+		t.Fatalf("unexpected header: %q", buf.String())
+	}
+	if lines[1] != "Bern,2024-01-01T12:56:00+01:00,0,IC,8,IC8,Zürich HB,6" {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+}
+
+func TestWriteConnectionsFormats(t *testing.T) {
+	var cr api.ConnectionsResponse
+	loadFixture(t, "connections.json", &cr)
+
+	var buf bytes.Buffer
+	if err := WriteConnections(&buf, FormatNDJSON, &cr); err != nil {
+		t.Fatalf("ndjson: %v", err)
+	}
+	var c Connection
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &c); err != nil || c.From != "Chur" {
+		t.Errorf("ndjson output not parseable: %v", err)
+	}
+
+	buf.Reset()
+	if err := WriteConnections(&buf, FormatCSV, &cr); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if !strings.Contains(buf.String(), "IC3 Chur-Zürich HB | S3 Zürich HB-Zürich Altstetten") {
+		t.Errorf("missing section summary: %q", buf.String())
+	}
+
+	if err := WriteConnections(&buf, "xml", &cr); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	if !ValidFormat(FormatNDJSON) || ValidFormat("yaml") {
+		t.Error("ValidFormat mismatch")
+	}
+}
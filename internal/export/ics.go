@@ -0,0 +1,145 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// WriteICS writes an RFC 5545 calendar with one VEVENT per section of the
+// connection. Walk legs without their own timestamps are placed after the
+// previous leg and last as long as the walk; a leading walk starts with the
+// connection or ends when the first journey leaves. now is used for DTSTAMP.
+func WriteICS(w io.Writer, conn *api.Connection, now time.Time) error {
+	if conn == nil {
+		return fmt.Errorf("no connection selected")
+	}
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//SBBuddy//Connection Export//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+
+	var prevEnd time.Time
+	for i, s := range conn.Sections {
+		if s.Journey == nil && s.Walk == nil {
+			continue
+		}
+		start, err := api.ParseTime(s.Departure.Departure)
+		if err != nil {
+			start = prevEnd
+			if start.IsZero() && s.Walk != nil {
+				start = leadingWalkStart(conn, i)
+			}
+		}
+		end, err := api.ParseTime(s.Arrival.Arrival)
+		if err != nil && s.Walk != nil {
+			end = start.Add(time.Duration(s.Walk.Duration) * time.Second)
+		}
+		if start.IsZero() || end.IsZero() {
+			return fmt.Errorf("section %d has no usable times", i+1)
+		}
+		prevEnd = end
+
+		from := s.Departure.Station.Name
+		to := s.Arrival.Station.Name
+		var summary, location, description string
+		if s.Journey != nil {
+			label := s.Journey.Category + s.Journey.Number
+			summary = fmt.Sprintf("%s %s → %s", label, from, to)
+			location = from
+			depPlatform := platform(s.Departure)
+			arrPlatform := platform(s.Arrival)
+			if depPlatform != "" {
+				location = fmt.Sprintf("%s, Platform %s", from, depPlatform)
+			}
+			desc := []string{fmt.Sprintf("%s towards %s", label, s.Journey.To)}
+			if s.Journey.Operator != "" {
+				desc = append(desc, "Operator: "+s.Journey.Operator)
+			}
+			desc = append(desc, fmt.Sprintf("Depart %s from %s%s", start.Local().Format("15:04"), from, platformSuffix(depPlatform)))
+			desc = append(desc, fmt.Sprintf("Arrive %s at %s%s", end.Local().Format("15:04"), to, platformSuffix(arrPlatform)))
+			description = strings.Join(desc, "\n")
+		} else {
+			summary = fmt.Sprintf("Walk %s → %s", from, to)
+			location = from
+			description = fmt.Sprintf("Walk from %s to %s (%d min)", from, to, s.Walk.Duration/60)
+		}
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s-%d@sbbuddy", start.UTC().Format(icsTimeLayout), i),
+			"DTSTAMP:"+now.UTC().Format(icsTimeLayout),
+			"DTSTART:"+start.UTC().Format(icsTimeLayout),
+			"DTEND:"+end.UTC().Format(icsTimeLayout),
+			"SUMMARY:"+escapeICSText(summary),
+			"LOCATION:"+escapeICSText(location),
+			"DESCRIPTION:"+escapeICSText(description),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, l := range lines {
+		if _, err := io.WriteString(w, foldICSLine(l)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func platformSuffix(p string) string {
+	if p == "" {
+		return ""
+	}
+	return ", Platform " + p
+}
+
+// leadingWalkStart returns the start of the walk in section i when no
+// earlier section has times: the departure of the connection, else the
+// departure of the next section less the walk. It is zero if neither is known.
+func leadingWalkStart(conn *api.Connection, i int) time.Time {
+	if t, err := api.ParseTime(conn.From.Departure); err == nil {
+		return t
+	}
+	if i+1 < len(conn.Sections) {
+		if t, err := api.ParseTime(conn.Sections[i+1].Departure.Departure); err == nil {
+			return t.Add(-time.Duration(conn.Sections[i].Walk.Duration) * time.Second)
+		}
+	}
+	return time.Time{}
+}
+
+// escapeICSText escapes TEXT values as required by RFC 5545 section 3.3.11.
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// foldICSLine splits content lines longer than 75 octets, continuing them on
+// lines starting with a space. Multi-byte characters are never split.
+func foldICSLine(l string) string {
+	const limit = 75
+	if len(l) <= limit {
+		return l
+	}
+	var b strings.Builder
+	lineLen := 0
+	for _, r := range l {
+		size := len(string(r))
+		if lineLen+size > limit {
+			b.WriteString("\r\n ")
+			lineLen = 1
+		}
+		b.WriteRune(r)
+		lineLen += size
+	}
+	return b.String()
+}
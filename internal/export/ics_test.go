@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+)
+
+func TestWriteICS(t *testing.T) {
+	var cr api.ConnectionsResponse
+	loadFixture(t, "connections.json", &cr)
+	conn := &cr.Connections[0]
+	conn.Sections = append(conn.Sections, api.Section{
+		Walk:      &api.Walk{Duration: 300},
+		Departure: api.Stop{Station: api.Location{Name: "Zürich Altstetten"}},
+		Arrival:   api.Stop{Station: api.Location{Name: "Office"}},
+	})
+
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	if err := WriteICS(&buf, conn, now); err != nil {
+		t.Fatalf("WriteICS error: %v", err)
+	}
+	out := buf.String()
+	if strings.Count(out, "BEGIN:VEVENT") != 3 {
+		t.Errorf("expected 3 events:\n%s", out)
+	}
+	checks := []string{
+		"DTSTART:20240101T113000Z",
+		"DTEND:20240101T130000Z",
+		"SUMMARY:IC3 Chur → Zürich HB",
+		`LOCATION:Chur\, Platform 1`,
+		"DTSTART:20240101T132000Z",
+		"DTEND:20240101T132500Z",
+		"DTSTAMP:20240101T080000Z",
+	}
+	for _, c := range checks {
+		if !strings.Contains(out, c) {
+			t.Errorf("output missing %q", c)
+		}
+	}
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line not folded: %q", l)
+		}
+	}
+}
+
+func TestWriteICSLeadingWalk(t *testing.T) {
+	var cr api.ConnectionsResponse
+	loadFixture(t, "connections.json", &cr)
+	walk := api.Section{
+		Walk:      &api.Walk{Duration: 420},
+		Departure: api.Stop{Station: api.Location{Name: "Home"}},
+		Arrival:   api.Stop{Station: api.Location{Name: "Chur"}},
+	}
+
+	for _, tc := range []struct {
+		name, from, start string
+	}{
+		{"connection departure", "2024-01-01T12:20:00+0100", "DTSTART:20240101T112000Z"},
+		{"before the journey", "", "DTSTART:20240101T112300Z"},
+	} {
+		conn := cr.Connections[0]
+		conn.From.Departure = tc.from
+		conn.Sections = append([]api.Section{walk}, conn.Sections...)
+
+		var buf bytes.Buffer
+		if err := WriteICS(&buf, &conn, time.Now()); err != nil {
+			t.Fatalf("%s: WriteICS error: %v", tc.name, err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, tc.start) || !strings.Contains(out, "SUMMARY:Walk Home → Chur") {
+			t.Errorf("%s: walk not placed:\n%s", tc.name, out)
+		}
+	}
+}
+
+func TestWriteICSNil(t *testing.T) {
+	if err := WriteICS(&bytes.Buffer{}, nil, time.Now()); err == nil {
+		t.Fatal("expected error for nil connection")
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	l := "DESCRIPTION:" + strings.Repeat("ü", 60)
+	folded := foldICSLine(l)
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("part too long: %d", len(part))
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != l {
+		t.Error("unfolding does not restore the original line")
+	}
+}
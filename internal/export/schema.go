@@ -0,0 +1,170 @@
+// Package export converts API responses into a stable, flat schema and writes
+// it in machine-readable formats for scripting.
+package export
+
+import (
+	"time"
+
+	api "SBBuddy/internal/api"
+)
+
+// Departure is one stationboard entry. Times are RFC3339 with the offset
+// reported by the API, delays are in minutes. Arrival and Origin are only
+// set on arrival boards.
+type Departure struct {
+	Station     string `json:"station"`
+	Departure   string `json:"departure"`
+	Arrival     string `json:"arrival,omitempty"`
+	Delay       int    `json:"delay"`
+	Category    string `json:"category"`
+	Number      string `json:"number"`
+	Line        string `json:"line"`
+	Origin      string `json:"origin,omitempty"`
+	Destination string `json:"destination"`
+	Platform    string `json:"platform"`
+}
+
+// Stationboard is the JSON document emitted for -T. Arrivals marks a board
+// of arriving trains (--arrivals).
+type Stationboard struct {
+	Station    string      `json:"station"`
+	Arrivals   bool        `json:"arrivals,omitempty"`
+	Departures []Departure `json:"departures"`
+}
+
+// Section is one leg of a connection. Type is "journey" or "walk"; walk legs
+// only carry stations, times and WalkMinutes.
+type Section struct {
+	Type              string `json:"type"`
+	Line              string `json:"line,omitempty"`
+	Category          string `json:"category,omitempty"`
+	Number            string `json:"number,omitempty"`
+	Operator          string `json:"operator,omitempty"`
+	Direction         string `json:"direction,omitempty"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Departure         string `json:"departure"`
+	Arrival           string `json:"arrival"`
+	DeparturePlatform string `json:"departurePlatform"`
+	ArrivalPlatform   string `json:"arrivalPlatform"`
+	DepartureDelay    int    `json:"departureDelay"`
+	ArrivalDelay      int    `json:"arrivalDelay"`
+	WalkMinutes       int    `json:"walkMinutes,omitempty"`
+}
+
+// Connection is one journey between origin and destination.
+type Connection struct {
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	Departure       string    `json:"departure"`
+	Arrival         string    `json:"arrival"`
+	DepartureDelay  int       `json:"departureDelay"`
+	ArrivalDelay    int       `json:"arrivalDelay"`
+	DurationMinutes int       `json:"durationMinutes"`
+	Changes         int       `json:"changes"`
+	Sections        []Section `json:"sections"`
+}
+
+// normalizeTime converts an API timestamp to RFC3339. Unparseable or empty
+// values yield an empty string.
+func normalizeTime(ts string) string {
+	t, err := api.ParseTime(ts)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// platform prefers the planned platform and falls back to the prognosis.
+func platform(s api.Stop) string {
+	if s.Platform != "" {
+		return s.Platform
+	}
+	return s.Prognosis.Platform
+}
+
+// NewStationboard converts a stationboard response into the export schema.
+func NewStationboard(sb *api.StationboardResponse) Stationboard {
+	out := Stationboard{Departures: []Departure{}}
+	if sb == nil {
+		return out
+	}
+	out.Station = sb.Station.Name
+	out.Arrivals = sb.Arrivals
+	for _, e := range sb.Stationboard {
+		d := Departure{
+			Station:     sb.EntryStation(e).Name,
+			Departure:   normalizeTime(e.Stop.Departure),
+			Delay:       e.Stop.Delay,
+			Category:    e.Category,
+			Number:      e.Number,
+			Line:        e.Category + e.Number,
+			Destination: e.To,
+			Platform:    platform(e.Stop),
+		}
+		if sb.Arrivals {
+			d.Arrival = normalizeTime(e.Stop.Arrival)
+			d.Origin = e.Origin()
+		}
+		out.Departures = append(out.Departures, d)
+	}
+	return out
+}
+
+// NewConnections converts a connections response into the export schema.
+func NewConnections(cr *api.ConnectionsResponse) []Connection {
+	out := []Connection{}
+	if cr == nil {
+		return out
+	}
+	for _, c := range cr.Connections {
+		conn := Connection{
+			From:           c.From.Station.Name,
+			To:             c.To.Station.Name,
+			Departure:      normalizeTime(c.From.Departure),
+			Arrival:        normalizeTime(c.To.Arrival),
+			DepartureDelay: c.From.Delay,
+			ArrivalDelay:   c.To.Delay,
+			Sections:       []Section{},
+		}
+		if dep, err := api.ParseTime(c.From.Departure); err == nil {
+			if arr, err := api.ParseTime(c.To.Arrival); err == nil {
+				conn.DurationMinutes = int(arr.Sub(dep).Minutes())
+			}
+		}
+		journeys := 0
+		for _, s := range c.Sections {
+			sec := Section{
+				From:              s.Departure.Station.Name,
+				To:                s.Arrival.Station.Name,
+				Departure:         normalizeTime(s.Departure.Departure),
+				Arrival:           normalizeTime(s.Arrival.Arrival),
+				DeparturePlatform: platform(s.Departure),
+				ArrivalPlatform:   platform(s.Arrival),
+				DepartureDelay:    s.Departure.Delay,
+				ArrivalDelay:      s.Arrival.Delay,
+			}
+			switch {
+			case s.Journey != nil:
+				journeys++
+				sec.Type = "journey"
+				sec.Line = s.Journey.Category + s.Journey.Number
+				sec.Category = s.Journey.Category
+				sec.Number = s.Journey.Number
+				sec.Operator = s.Journey.Operator
+				sec.Direction = s.Journey.To
+			case s.Walk != nil:
+				sec.Type = "walk"
+				sec.WalkMinutes = s.Walk.Duration / 60
+			default:
+				continue
+			}
+			conn.Sections = append(conn.Sections, sec)
+		}
+		if journeys > 1 {
+			conn.Changes = journeys - 1
+		}
+		out = append(out, conn)
+	}
+	return out
+}
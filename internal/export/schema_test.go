@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "SBBuddy/internal/api"
+)
+
+func loadFixture(t *testing.T, name string, v interface{}) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("unmarshal %s: %v", name, err)
+	}
+}
+
+func TestNewStationboard(t *testing.T) {
+	var sb api.StationboardResponse
+	loadFixture(t, "stationboard.json", &sb)
+	board := NewStationboard(&sb)
+	if board.Station != "Chur" || len(board.Departures) != 1 {
+		t.Fatalf("unexpected board: %+v", board)
+	}
+	d := board.Departures[0]
+	if d.Line != "RE1234" || d.Destination != "Basel SBB" || d.Platform != "1" {
+		t.Errorf("unexpected departure: %+v", d)
+	}
+	if d.Departure != "2024-01-01T12:30:00+01:00" {
+		t.Errorf("unexpected departure time %s", d.Departure)
+	}
+}
+
+func TestNewStationboardCombined(t *testing.T) {
+	e := api.StationboardEntry{Category: "S", Number: "1"}
+	e.Stop.Station = api.Location{Name: "Bern Wankdorf"}
+	sb := &api.StationboardResponse{
+		Station:      api.Location{Name: "Bern + Bern Wankdorf"},
+		Stations:     []api.Location{{Name: "Bern"}, {Name: "Bern Wankdorf"}},
+		Stationboard: []api.StationboardEntry{e},
+	}
+	board := NewStationboard(sb)
+	if board.Station != "Bern + Bern Wankdorf" || board.Departures[0].Station != "Bern Wankdorf" {
+		t.Errorf("expected the station of the departure, got %+v", board)
+	}
+}
+
+func TestNewConnections(t *testing.T) {
+	var cr api.ConnectionsResponse
+	loadFixture(t, "connections.json", &cr)
+	conns := NewConnections(&cr)
+	if len(conns) != 1 {
+		t.Fatalf("expected 1 connection, got %d", len(conns))
+	}
+	c := conns[0]
+	if c.DurationMinutes != 110 || c.Changes != 1 || len(c.Sections) != 2 {
+		t.Errorf("unexpected connection: %+v", c)
+	}
+	if c.Sections[0].Type != "journey" || c.Sections[0].Line != "IC3" || c.Sections[1].DeparturePlatform != "6" {
+		t.Errorf("unexpected sections: %+v", c.Sections)
+	}
+}
+
+func TestNewConnectionsNil(t *testing.T) {
+	if conns := NewConnections(nil); conns == nil || len(conns) != 0 {
+		t.Errorf("expected empty non-nil slice")
+	}
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Favorite is a saved station or route. A single station opens its
+// stationboard; two or more stations form a route from the first to the last
+// station via the ones in between. WalkMinutes is how long it takes to walk
+// to a single station; its stationboard then only lists the departures that
+// can still be caught.
+type Favorite struct {
+	Name        string   `json:"name"`
+	Stations    []string `json:"stations"`
+	WalkMinutes int      `json:"walkMinutes,omitempty"`
+}
+
+// MaxWalkMinutes is the longest walk to a favorite station.
+const MaxWalkMinutes = 60
+
+// IsRoute reports whether the favorite describes a connection search.
+func (f Favorite) IsRoute() bool {
+	return len(f.Stations) >= 2
+}
+
+// From returns the origin (or the only station).
+func (f Favorite) From() string {
+	if len(f.Stations) == 0 {
+		return ""
+	}
+	return f.Stations[0]
+}
+
+// To returns the destination of a route.
+func (f Favorite) To() string {
+	if !f.IsRoute() {
+		return ""
+	}
+	return f.Stations[len(f.Stations)-1]
+}
+
+// Via returns the via stations of a route.
+func (f Favorite) Via() []string {
+	if len(f.Stations) <= 2 {
+		return nil
+	}
+	return f.Stations[1 : len(f.Stations)-1]
+}
+
+// Label describes the favorite for menus, e.g. "Home → Office",
+// "office (Bern → Zürich HB)" or "home (Chur, 7 min walk)".
+func (f Favorite) Label() string {
+	target := strings.Join(f.Stations, " → ")
+	walk := ""
+	if f.WalkMinutes > 0 && !f.IsRoute() {
+		walk = fmt.Sprintf("%d min walk", f.WalkMinutes)
+	}
+	switch {
+	case (f.Name == "" || f.Name == target) && walk == "":
+		return target
+	case f.Name == "" || f.Name == target:
+		return target + " (" + walk + ")"
+	case walk != "":
+		return f.Name + " (" + target + ", " + walk + ")"
+	}
+	return f.Name + " (" + target + ")"
+}
+
+// Favorites is the persisted list of favorites.
+type Favorites struct {
+	path  string
+	Items []Favorite `json:"favorites"`
+}
+
+// LoadFavorites reads the favorites file at path.
+func LoadFavorites(path string) (*Favorites, error) {
+	f := &Favorites{path: path}
+	if err := loadJSON(path, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Save writes the favorites back to disk.
+func (f *Favorites) Save() error {
+	return saveJSON(f.path, f)
+}
+
+// Find returns the favorite with the given name, ignoring case.
+func (f *Favorites) Find(name string) (Favorite, bool) {
+	for _, fav := range f.Items {
+		if strings.EqualFold(fav.Name, strings.TrimSpace(name)) {
+			return fav, true
+		}
+	}
+	return Favorite{}, false
+}
+
+// Add stores fav, replacing an existing favorite with the same name.
+func (f *Favorites) Add(fav Favorite) {
+	fav.Name = strings.TrimSpace(fav.Name)
+	for i, existing := range f.Items {
+		if strings.EqualFold(existing.Name, fav.Name) {
+			f.Items[i] = fav
+			return
+		}
+	}
+	f.Items = append(f.Items, fav)
+}
+
+// Remove deletes the favorite at index i.
+func (f *Favorites) Remove(i int) {
+	if i < 0 || i >= len(f.Items) {
+		return
+	}
+	f.Items = append(f.Items[:i], f.Items[i+1:]...)
+}
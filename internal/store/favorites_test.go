@@ -0,0 +1,72 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFavoriteAccessors(t *testing.T) {
+	route := Favorite{Name: "office", Stations: []string{"Bern", "Olten", "Zürich HB"}}
+	if !route.IsRoute() || route.From() != "Bern" || route.To() != "Zürich HB" {
+		t.Errorf("unexpected route accessors")
+	}
+	if via := route.Via(); len(via) != 1 || via[0] != "Olten" {
+		t.Errorf("unexpected via %v", via)
+	}
+	if route.Label() != "office (Bern → Olten → Zürich HB)" {
+		t.Errorf("unexpected label %s", route.Label())
+	}
+	station := Favorite{Stations: []string{"Chur"}}
+	if station.IsRoute() || station.To() != "" || station.Label() != "Chur" {
+		t.Errorf("unexpected station accessors")
+	}
+	station.WalkMinutes = 7
+	if station.Label() != "Chur (7 min walk)" {
+		t.Errorf("unexpected label %s", station.Label())
+	}
+	station.Name = "home"
+	if station.Label() != "home (Chur, 7 min walk)" {
+		t.Errorf("unexpected label %s", station.Label())
+	}
+}
+
+func TestFavoritesPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	favs, err := LoadFavorites(path)
+	if err != nil {
+		t.Fatalf("load empty: %v", err)
+	}
+	favs.Add(Favorite{Name: "Office", Stations: []string{"Bern", "Zürich HB"}})
+	favs.Add(Favorite{Name: "home", Stations: []string{"Chur"}})
+	favs.Add(Favorite{Name: "office", Stations: []string{"Bern", "Basel SBB"}})
+	if err := favs.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := LoadFavorites(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if len(loaded.Items) != 2 {
+		t.Fatalf("expected 2 favorites, got %d", len(loaded.Items))
+	}
+	fav, ok := loaded.Find("OFFICE")
+	if !ok || fav.To() != "Basel SBB" {
+		t.Errorf("expected replaced office favorite, got %+v", fav)
+	}
+	loaded.Remove(0)
+	if _, ok := loaded.Find("office"); ok {
+		t.Errorf("favorite not removed")
+	}
+}
+
+func TestOpenInMemory(t *testing.T) {
+	st, err := Open("")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	st.Favorites.Add(Favorite{Name: "x", Stations: []string{"Bern"}})
+	if err := st.Favorites.Save(); err != nil {
+		t.Errorf("in-memory save should be a no-op: %v", err)
+	}
+}
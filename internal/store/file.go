@@ -0,0 +1,90 @@
+// Package store persists user data such as favorites, the search history and
+// preferences as JSON files in the SBBuddy data directory.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadJSON decodes the file at path into v. A missing file leaves v untouched.
+func loadJSON(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// saveJSON atomically writes v to path. An empty path keeps data in memory
+// only, which is what tests and InitialModel use.
+func saveJSON(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Store bundles the user data files.
+type Store struct {
+	Favorites   *Favorites
+	History     *History
+	Preferences *Preferences
+}
+
+// Open loads the user data files from dir. An empty dir yields an in-memory
+// store whose changes are never written.
+func Open(dir string) (*Store, error) {
+	path := func(name string) string {
+		if dir == "" {
+			return ""
+		}
+		return filepath.Join(dir, name)
+	}
+	favs, err := LoadFavorites(path("favorites.json"))
+	if err != nil {
+		return nil, err
+	}
+	history, err := LoadHistory(path("history.json"))
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := LoadPreferences(path("preferences.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Favorites: favs, History: history, Preferences: prefs}, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// maxHistory caps the number of remembered searches.
+const maxHistory = 50
+
+// Search is a successful stationboard (one station) or connection search
+// (from, via..., to) remembered in the history.
+type Search struct {
+	Stations []string  `json:"stations"`
+	At       time.Time `json:"at"`
+}
+
+// Favorite converts the search so it can be opened like a favorite.
+func (s Search) Favorite() Favorite {
+	return Favorite{Stations: s.Stations}
+}
+
+// History is the persisted list of recent searches, most recent first.
+type History struct {
+	path     string
+	Searches []Search `json:"searches"`
+}
+
+// LoadHistory reads the history file at path.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if err := loadJSON(path, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Save writes the history back to disk.
+func (h *History) Save() error {
+	return saveJSON(h.path, h)
+}
+
+// Record moves the search to the front of the history, dropping older
+// duplicates and the oldest entries beyond maxHistory.
+func (h *History) Record(stations []string, at time.Time) {
+	if len(stations) == 0 {
+		return
+	}
+	key := strings.ToLower(strings.Join(stations, "\x00"))
+	searches := []Search{{Stations: append([]string{}, stations...), At: at}}
+	for _, s := range h.Searches {
+		if strings.ToLower(strings.Join(s.Stations, "\x00")) == key {
+			continue
+		}
+		searches = append(searches, s)
+	}
+	if len(searches) > maxHistory {
+		searches = searches[:maxHistory]
+	}
+	h.Searches = searches
+}
+
+// Stations returns the distinct station names of the history, most recently
+// used first.
+func (h *History) Stations() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range h.Searches {
+		for _, name := range s.Stations {
+			k := strings.ToLower(name)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryRecord(t *testing.T) {
+	h, err := LoadHistory(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t0 := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	h.Record([]string{"Zürich HB"}, t0)
+	h.Record([]string{"Bern", "Zürich HB"}, t0.Add(time.Minute))
+	h.Record([]string{"zürich hb"}, t0.Add(2*time.Minute))
+
+	if len(h.Searches) != 2 {
+		t.Fatalf("expected duplicates to be merged, got %d searches", len(h.Searches))
+	}
+	if h.Searches[0].Stations[0] != "zürich hb" || !h.Searches[0].At.Equal(t0.Add(2*time.Minute)) {
+		t.Errorf("most recent search should come first: %+v", h.Searches[0])
+	}
+	stations := h.Stations()
+	if len(stations) != 2 || stations[0] != "zürich hb" || stations[1] != "Bern" {
+		t.Errorf("unexpected stations %v", stations)
+	}
+}
+
+func TestHistoryLimitAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h, _ := LoadHistory(path)
+	for i := 0; i < maxHistory+5; i++ {
+		h.Record([]string{time.Duration(i).String()}, time.Now())
+	}
+	if len(h.Searches) != maxHistory {
+		t.Fatalf("expected %d searches, got %d", maxHistory, len(h.Searches))
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := LoadHistory(path)
+	if err != nil || len(loaded.Searches) != maxHistory {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if fav := loaded.Searches[0].Favorite(); fav.From() != h.Searches[0].Stations[0] {
+		t.Errorf("unexpected favorite conversion %+v", fav)
+	}
+}
@@ -0,0 +1,23 @@
+package store
+
+// Preferences holds defaults chosen in the TUI that apply to later searches.
+type Preferences struct {
+	path string
+	// Transportations restricts results to these transport types (train,
+	// tram, ship, bus, cableway). Empty means all types.
+	Transportations []string `json:"transportations,omitempty"`
+}
+
+// LoadPreferences reads the preferences file at path.
+func LoadPreferences(path string) (*Preferences, error) {
+	p := &Preferences{path: path}
+	if err := loadJSON(path, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Save writes the preferences back to disk.
+func (p *Preferences) Save() error {
+	return saveJSON(p.path, p)
+}
@@ -0,0 +1,22 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPreferencesPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	p, err := LoadPreferences(path)
+	if err != nil || len(p.Transportations) != 0 {
+		t.Fatalf("expected empty preferences, got %+v (%v)", p, err)
+	}
+	p.Transportations = []string{"train", "ship"}
+	if err := p.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := LoadPreferences(path)
+	if err != nil || len(loaded.Transportations) != 2 || loaded.Transportations[1] != "ship" {
+		t.Errorf("preferences not persisted: %+v (%v)", loaded, err)
+	}
+}
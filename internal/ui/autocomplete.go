@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// autocompleteDelay is how long typing has to pause before a lookup.
+	autocompleteDelay = 250 * time.Millisecond
+	// maxAutocomplete limits the suggestions shown under the input.
+	maxAutocomplete = 5
+)
+
+// autocompleteState holds the as-you-type suggestions of the active station
+// input. seq identifies the latest keystroke so debounce ticks and lookups
+// started for older text can be recognized and dropped.
+type autocompleteState struct {
+	input   *textinput.Model
+	query   string
+	seq     int
+	cancel  context.CancelFunc
+	results []api.Location
+	index   int // highlighted suggestion, -1 for none
+}
+
+// autocompleteTickMsg fires once typing paused for autocompleteDelay.
+type autocompleteTickMsg struct {
+	seq   int
+	query string
+}
+
+// autocompleteMsg carries the result of a suggestion lookup.
+type autocompleteMsg struct {
+	seq      int
+	stations []api.Location
+	err      error
+}
+
+var autocompleteSelected = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+
+// activeStationInput returns the station input edited in the current state,
+// or nil when no station input has focus.
+func (m *Model) activeStationInput() *textinput.Model {
+	switch m.state {
+	case stateStationInput:
+		return &m.stationInput
+	case stateConnectionInputFrom:
+		return &m.fromInput
+	case stateConnectionInputTo:
+		return &m.toInput
+	case stateConnectionInputVia:
+		if m.viaIndex < len(m.viaInputs) {
+			return &m.viaInputs[m.viaIndex]
+		}
+	}
+	return nil
+}
+
+// resetAutocomplete cancels a running lookup and clears the suggestions.
+func (m *Model) resetAutocomplete() {
+	if m.autocomplete.cancel != nil {
+		m.autocomplete.cancel()
+	}
+	m.autocomplete = autocompleteState{seq: m.autocomplete.seq + 1, index: -1}
+}
+
+// updateStationInput forwards msg to a station input and schedules a
+// debounced suggestion lookup whenever its text changed.
+func (m *Model) updateStationInput(input *textinput.Model, msg tea.Msg) tea.Cmd {
+	before := input.Value()
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	if input.Value() == before {
+		return cmd
+	}
+	m.resetAutocomplete()
+	query := strings.TrimSpace(input.Value())
+	m.autocomplete.input = input
+	m.autocomplete.query = query
+	if len([]rune(query)) < 2 {
+		return cmd
+	}
+	seq := m.autocomplete.seq
+	tick := tea.Tick(autocompleteDelay, func(time.Time) tea.Msg {
+		return autocompleteTickMsg{seq: seq, query: query}
+	})
+	return tea.Batch(cmd, tick)
+}
+
+// handleAutocompleteMsg processes debounce ticks and lookup results. Messages
+// belonging to superseded keystrokes are ignored.
+func (m *Model) handleAutocompleteMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case autocompleteTickMsg:
+		if msg.seq != m.autocomplete.seq || m.autocomplete.input != m.activeStationInput() {
+			return nil
+		}
+		// prefixes typed on the way are not worth keeping in the disk cache
+		ctx, cancel := context.WithCancel(api.NoCacheStore(context.Background()))
+		m.autocomplete.cancel = cancel
+		client := m.api
+		return func() tea.Msg {
+			stations, err := client.SearchStations(ctx, msg.query)
+			return autocompleteMsg{seq: msg.seq, stations: stations, err: err}
+		}
+	case autocompleteMsg:
+		if msg.seq != m.autocomplete.seq || m.autocomplete.input != m.activeStationInput() {
+			return nil
+		}
+		m.autocomplete.cancel = nil
+		// Lookup errors are not worth interrupting typing; Enter still
+		// validates the input and reports failures.
+		if msg.err == nil {
+			m.autocomplete.results = msg.stations
+			if len(m.autocomplete.results) > maxAutocomplete {
+				m.autocomplete.results = m.autocomplete.results[:maxAutocomplete]
+			}
+			m.autocomplete.index = -1
+		}
+	}
+	return nil
+}
+
+// showsAutocomplete reports whether suggestions for input are current and
+// should be offered.
+func (m *Model) showsAutocomplete(input *textinput.Model) bool {
+	ac := &m.autocomplete
+	return input != nil && ac.input == input && input == m.activeStationInput() &&
+		strings.TrimSpace(input.Value()) == ac.query && len(ac.results) > 0
+}
+
+// handleAutocompleteKey moves the highlight through the suggestions and picks
+// the highlighted one on Enter. It reports whether the key was consumed.
+func (m *Model) handleAutocompleteKey(msg tea.KeyMsg) bool {
+	ac := &m.autocomplete
+	if !m.showsAutocomplete(m.activeStationInput()) {
+		return false
+	}
+	n := len(ac.results)
+	switch {
+	case key.Matches(msg, m.keys.NextSuggestion):
+		ac.index = (ac.index + 1) % n
+		return true
+	case key.Matches(msg, m.keys.PrevSuggestion):
+		if ac.index <= 0 {
+			ac.index = n - 1
+		} else {
+			ac.index--
+		}
+		return true
+	case key.Matches(msg, m.keys.Enter) && ac.index >= 0:
+		loc := ac.results[ac.index]
+		ac.input.SetValue(loc.Name)
+		m.chooseStation(&loc)
+		return true
+	}
+	return false
+}
+
+// autocompleteView renders the suggestions for the given input.
+func (m *Model) autocompleteView(input *textinput.Model) string {
+	if !m.showsAutocomplete(input) {
+		return ""
+	}
+	var sb strings.Builder
+	for i, loc := range m.autocomplete.results {
+		line := fmt.Sprintf("  %s", loc.Name)
+		if i == m.autocomplete.index {
+			line = autocompleteSelected.Render(fmt.Sprintf("› %s", loc.Name))
+		}
+		sb.WriteString("\n" + line)
+	}
+	return sb.String()
+}
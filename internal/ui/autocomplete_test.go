@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newFixtureModel(t *testing.T) *Model {
+	t.Helper()
+	st, _ := store.Open("")
+	p := api.NewFixtureProvider(filepath.Join("..", "..", "testdata"))
+	return NewModel(api.NewClientWithProvider(p), st)
+}
+
+func typeInto(m *Model, text string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, r := range text {
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return cmd
+}
+
+func TestAutocompleteDebounce(t *testing.T) {
+	m := newFixtureModel(t)
+	m.state = stateStationInput
+	typeInto(m, "Be")
+	first := m.autocomplete.seq
+	typeInto(m, "r")
+
+	// The tick of the superseded keystroke must not start a lookup.
+	if _, cmd := m.Update(autocompleteTickMsg{seq: first, query: "Be"}); cmd != nil {
+		t.Errorf("expected stale tick to be dropped")
+	}
+	_, cmd := m.Update(autocompleteTickMsg{seq: m.autocomplete.seq, query: "Ber"})
+	if cmd == nil {
+		t.Fatalf("expected lookup for current tick")
+	}
+	m.Update(cmd())
+	if len(m.autocomplete.results) == 0 || len(m.autocomplete.results) > maxAutocomplete {
+		t.Fatalf("unexpected suggestions %v", m.autocomplete.results)
+	}
+	if m.autocompleteView(&m.stationInput) == "" {
+		t.Errorf("expected suggestions under the input")
+	}
+}
+
+func TestAutocompleteStaleResultDropped(t *testing.T) {
+	m := newFixtureModel(t)
+	m.state = stateStationInput
+	typeInto(m, "Be")
+	seq := m.autocomplete.seq
+	typeInto(m, "r")
+
+	m.Update(autocompleteMsg{seq: seq, stations: []api.Location{{Name: "Bern"}}})
+	if len(m.autocomplete.results) != 0 {
+		t.Errorf("expected result for old text to be ignored")
+	}
+}
+
+func TestAutocompleteSelectSuggestion(t *testing.T) {
+	m := newFixtureModel(t)
+	m.state = stateConnectionInputFrom
+	m.fromInput.Focus()
+	typeInto(m, "Be")
+	m.Update(autocompleteMsg{seq: m.autocomplete.seq, stations: []api.Location{{Name: "Bern"}, {Name: "Bern Wankdorf"}}})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.fromStation == nil || m.fromStation.Name != "Bern Wankdorf" {
+		t.Fatalf("expected highlighted suggestion to be chosen, got %+v", m.fromStation)
+	}
+	if m.state != stateConnectionInputTo {
+		t.Errorf("expected to continue with destination, got %v", m.state)
+	}
+	if m.autocompleteView(&m.toInput) != "" {
+		t.Errorf("suggestions should not carry over to the next input")
+	}
+}
@@ -0,0 +1,242 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// minEarlierWindow and maxEarlierWindow bound how long before the
+	// earliest listed connection earlier connections are searched.
+	minEarlierWindow = time.Hour
+	maxEarlierWindow = 24 * time.Hour
+)
+
+// connectionsPageMsg carries further connections of the search shown,
+// requested by scrolling past either end of the list.
+type connectionsPageMsg struct {
+	id      int
+	later   bool
+	page    int
+	earlier earlierSearch // the search of an earlier request
+	cr      *api.ConnectionsResponse
+	err     error
+}
+
+// earlierSearch looks for the connections leaving before until, the
+// earliest one listed: a departure search starting window before until
+// whose pages are followed until they reach until.
+type earlierSearch struct {
+	until  time.Time
+	window time.Duration
+}
+
+// connPagingState tracks the further connections merged into the list.
+// Later ones are the following pages of the search, page 0 being the search
+// itself. Earlier ones are searched by departure before the earliest listed
+// connection, as the API has no pages before the first.
+type connPagingState struct {
+	last        int // latest page merged
+	loading     bool
+	earlierDone bool // the last earlier search added no connections
+	laterDone   bool // the last later page added no connections
+}
+
+// SetConnectionLimit sets how many connections are fetched per page. Values
+// outside 1 to api.MaxConnectionLimit are ignored.
+func (m *Model) SetConnectionLimit(limit int) {
+	if limit > 0 && limit <= api.MaxConnectionLimit {
+		m.connectionLimit = limit
+	}
+}
+
+// showConnections displays the result of a new search. For arrival searches
+// the cursor starts on the connection arriving closest to the searched time.
+func (m *Model) showConnections(cr *api.ConnectionsResponse) {
+	m.connections = cr
+	m.connPaging = connPagingState{}
+	m.connTable = m.buildConnTable()
+	m.cursor = 0
+	if cr != nil && m.lastSearchArrival && !m.lastSearchTime.IsZero() {
+		m.cursor = max(closestArrival(cr.Connections, m.lastSearchTime), 0)
+		m.connTable.SetCursor(m.cursor)
+	}
+}
+
+// loadConnectionPage fetches the page after the latest page of the search
+// shown, or the connections leaving before the earliest one listed.
+func (m *Model) loadConnectionPage(later bool) tea.Cmd {
+	p := m.connPaging
+	if p.loading || m.connections == nil || m.fromStation == nil || m.toStation == nil {
+		return nil
+	}
+	if (later && p.laterDone) || (!later && p.earlierDone) {
+		return nil
+	}
+	if later {
+		return m.fetchConnectionPage(true, p.last+1, earlierSearch{})
+	}
+
+	conns := m.connections.Connections
+	if len(conns) == 0 {
+		return nil
+	}
+	first, err := parseAPITime(conns[0].From.Departure)
+	if err != nil {
+		return nil
+	}
+	// start as long before as a page of the listed connections lasts
+	window := minEarlierWindow
+	if n := min(len(conns), max(m.connectionLimit, 1)); n > 1 {
+		if last, err := parseAPITime(conns[n-1].From.Departure); err == nil {
+			window = min(max(window, last.Sub(first)), maxEarlierWindow)
+		}
+	}
+	return m.fetchConnectionPage(false, 0, earlierSearch{until: first, window: window})
+}
+
+// fetchConnectionPage fetches a page of the search shown, or of the earlier
+// search when later is false.
+func (m *Model) fetchConnectionPage(later bool, page int, earlier earlierSearch) tea.Cmd {
+	ctx, id := m.startFetch()
+	m.connPaging.loading = true
+	opts := m.connectionOptions(m.lastSearchArrival)
+	opts.Page = page
+	date, tm := "", ""
+	if !m.lastSearchTime.IsZero() {
+		date, tm = m.lastSearchTime.Format("2006-01-02"), m.lastSearchTime.Format("15:04")
+	}
+	if !later {
+		start := earlier.until.Add(-earlier.window)
+		opts.Arrival = false
+		date, tm = start.Format("2006-01-02"), start.Format("15:04")
+	}
+	client, from, to, via := m.api, m.fromStation.Name, m.toStation.Name, m.viaNames()
+	return func() tea.Msg {
+		cr, err := client.FetchConnectionsWith(ctx, from, to, via, date, tm, opts)
+		return connectionsPageMsg{id: id, later: later, page: page, earlier: earlier, cr: cr, err: err}
+	}
+}
+
+// handleConnectionsPage merges a page into the list and moves the cursor
+// onto the first new connection in the direction scrolled. An earlier search
+// continues until it reaches the connections listed before.
+func (m *Model) handleConnectionsPage(msg connectionsPageMsg) tea.Cmd {
+	if !m.finishFetch(msg.id) {
+		return nil
+	}
+	m.connPaging.loading = false
+	later := msg.later
+	if msg.err != nil {
+		m.status = fmt.Sprintf("❌ failed to load more connections: %v", msg.err)
+		return nil
+	}
+
+	var next tea.Cmd
+	if !later && msg.cr != nil {
+		before, reached := 0, len(msg.cr.Connections) < m.connectionLimit
+		for _, c := range msg.cr.Connections {
+			if t, err := parseAPITime(c.From.Departure); err == nil && t.Before(msg.earlier.until) {
+				before++
+			} else {
+				reached = true
+			}
+		}
+		switch {
+		case before == 0 && msg.earlier.window < maxEarlierWindow:
+			// nothing leaves within the window, look further back
+			wider := msg.earlier
+			wider.window = min(2*wider.window, maxEarlierWindow)
+			return m.fetchConnectionPage(false, 0, wider)
+		case before > 0 && !reached:
+			next = m.fetchConnectionPage(false, msg.page+1, msg.earlier)
+		}
+	}
+
+	known := make(map[string]bool, len(m.connections.Connections))
+	for _, c := range m.connections.Connections {
+		known[connectionKey(c)] = true
+	}
+	var added int
+	if msg.cr != nil {
+		m.connections.Connections, added = mergeConnections(m.connections.Connections, msg.cr.Connections)
+	}
+	if later {
+		m.connPaging.last = msg.page
+	}
+	if added == 0 {
+		if later {
+			m.connPaging.laterDone = true
+			m.status = "No later connections"
+		} else if msg.page == 0 {
+			m.connPaging.earlierDone = true
+			m.status = "No earlier connections"
+		}
+		return next
+	}
+
+	m.status = ""
+	m.connTable = m.buildConnTable()
+	cursor := -1
+	for i, c := range m.connections.Connections {
+		if known[connectionKey(c)] {
+			continue
+		}
+		if cursor < 0 || !later {
+			cursor = i
+		}
+		if later {
+			break
+		}
+	}
+	m.connTable.SetCursor(cursor)
+	m.cursor = cursor
+	return next
+}
+
+// mergeConnections adds the connections of more that are not in conns yet
+// and keeps the list ordered by departure. It returns the merged list and
+// the number of connections added.
+func mergeConnections(conns, more []api.Connection) ([]api.Connection, int) {
+	seen := make(map[string]bool, len(conns))
+	for _, c := range conns {
+		seen[connectionKey(c)] = true
+	}
+	added := 0
+	for _, c := range more {
+		k := connectionKey(c)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		conns = append(conns, c)
+		added++
+	}
+	sort.SliceStable(conns, func(i, j int) bool {
+		a, errA := parseAPITime(conns[i].From.Departure)
+		b, errB := parseAPITime(conns[j].From.Departure)
+		if errA != nil || errB != nil || a.Equal(b) {
+			return conns[i].To.Arrival < conns[j].To.Arrival
+		}
+		return a.Before(b)
+	})
+	return conns, added
+}
+
+// connectionKey identifies a connection across result pages by its times
+// and the trains taken.
+func connectionKey(c api.Connection) string {
+	parts := []string{c.From.Departure, c.To.Arrival}
+	for _, s := range c.Sections {
+		if s.Journey != nil {
+			parts = append(parts, s.Journey.Category+s.Journey.Number)
+		}
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pagedConnProvider serves the hourly connections of 1 January 2024,
+// leaving on the hour and arriving at half past, and any extra ones. A search
+// lists three connections from the time searched, 10:00 unless the search is
+// for that day. Page p starts two connections later so neighbouring pages
+// overlap by one; pages of the 10:00 search beyond maxPage are empty.
+type pagedConnProvider struct {
+	*api.FixtureProvider
+	maxPage  int
+	extra    []api.Connection
+	opts     []api.ConnectionOptions
+	requests []string
+}
+
+func pagedConn(dep, arr, number string) api.Connection {
+	var c api.Connection
+	c.From.Departure = "2024-01-01T" + dep + ":00+0100"
+	c.To.Arrival = "2024-01-01T" + arr + ":00+0100"
+	c.Sections = []api.Section{{Journey: &api.Journey{Category: "IC", Number: number}}}
+	return c
+}
+
+func (p *pagedConnProvider) Connections(ctx context.Context, from, to string, via []string, date, timeStr string, opts api.ConnectionOptions) (*api.ConnectionsResponse, error) {
+	p.opts = append(p.opts, opts)
+	cr := &api.ConnectionsResponse{}
+	start := "10:00"
+	if date == "2024-01-01" {
+		start = timeStr
+	}
+	p.requests = append(p.requests, fmt.Sprintf("%s page %d", start, opts.Page))
+	if date != "2024-01-01" && opts.Page > p.maxPage {
+		return cr, nil
+	}
+
+	all := append([]api.Connection{}, p.extra...)
+	for h := 0; h < 24; h++ {
+		all = append(all, pagedConn(fmt.Sprintf("%02d:00", h), fmt.Sprintf("%02d:30", h), fmt.Sprint(h)))
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].From.Departure < all[j].From.Departure })
+	i := sort.Search(len(all), func(i int) bool { return all[i].From.Departure[11:16] >= start })
+	i += 2 * opts.Page
+	cr.Connections = all[min(i, len(all)):min(i+3, len(all))]
+	return cr, nil
+}
+
+func newPagedConnModel(t *testing.T) (*Model, *pagedConnProvider) {
+	t.Helper()
+	st, _ := store.Open("")
+	p := &pagedConnProvider{FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata")), maxPage: 1}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	m.SetConnectionLimit(3)
+	cmd := m.openFavorite(store.Favorite{Name: "office", Stations: []string{"Chur", "Bern"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	return m, p
+}
+
+func TestConnectionsPaging(t *testing.T) {
+	m, p := newPagedConnModel(t)
+	if m.state != stateShowConnections || len(m.connections.Connections) != 3 {
+		t.Fatalf("unexpected connections %+v in state %v", m.connections, m.state)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if cmd == nil {
+		t.Fatalf("expected the next page to be fetched")
+	}
+	m.Update(cmd())
+	if got := len(m.connections.Connections); got != 5 {
+		t.Fatalf("expected 5 connections after merging, got %d", got)
+	}
+	if m.connTable.Cursor() != 3 {
+		t.Errorf("cursor should move onto the first new connection, got %d", m.connTable.Cursor())
+	}
+
+	// scrolling above the first connection searches the ones leaving before
+	m.connTable.SetCursor(0)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if cmd == nil {
+		t.Fatalf("expected earlier connections to be fetched")
+	}
+	m.Update(cmd())
+	conns := m.connections.Connections
+	if len(conns) != 7 || conns[0].From.Departure != "2024-01-01T08:00:00+0100" || conns[6].From.Departure != "2024-01-01T14:00:00+0100" {
+		t.Fatalf("expected a continuous list from 08:00 to 14:00, got %d connections", len(conns))
+	}
+	if m.connTable.Cursor() != 1 {
+		t.Errorf("cursor should move onto the 09:00 connection, got %d", m.connTable.Cursor())
+	}
+
+	for _, o := range p.opts {
+		if o.Limit != 3 || o.Page < 0 {
+			t.Errorf("expected configured limit and no negative page in every request, got %+v", o)
+		}
+	}
+	if got := strings.Join(p.requests, ", "); got != "10:00 page 0, 10:00 page 1, 08:00 page 0" {
+		t.Errorf("unexpected requests: %s", got)
+	}
+}
+
+func TestConnectionsPagingEarlierSlow(t *testing.T) {
+	m, p := newPagedConnModel(t)
+	// leaves before the first connection listed but arrives after it
+	p.extra = []api.Connection{pagedConn("09:45", "11:40", "slow")}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if cmd == nil {
+		t.Fatalf("expected earlier connections to be fetched")
+	}
+	// a full page before 10:00 is followed by the next one
+	for ; cmd != nil; _, cmd = m.Update(cmd()) {
+	}
+	var got []string
+	for _, c := range m.connections.Connections {
+		got = append(got, c.From.Departure[11:16])
+	}
+	if strings.Join(got, " ") != "08:00 09:00 09:45 10:00 11:00 12:00" {
+		t.Errorf("unexpected connections %v", got)
+	}
+	if m.connPaging.earlierDone || m.connTable.Cursor() != 2 {
+		t.Errorf("expected the cursor on the 09:45 connection, got %d (%+v)", m.connTable.Cursor(), m.connPaging)
+	}
+	if got := strings.Join(p.requests, ", "); got != "10:00 page 0, 08:00 page 0, 08:00 page 1" {
+		t.Errorf("unexpected requests: %s", got)
+	}
+}
+
+func TestConnectionsPagingExhausted(t *testing.T) {
+	m, p := newPagedConnModel(t)
+	p.maxPage = 0
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m.Update(cmd())
+	if !m.connPaging.laterDone || m.status != "No later connections" {
+		t.Errorf("expected end of list, got %+v", m.connPaging)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRight}); cmd != nil {
+		t.Errorf("no further request expected after the end")
+	}
+}
+
+func TestConnectionsPagingStale(t *testing.T) {
+	m, _ := newPagedConnModel(t)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	page := cmd()
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m.Update(page)
+	if m.connections != nil && len(m.connections.Connections) != 3 {
+		t.Errorf("stale page was merged")
+	}
+}
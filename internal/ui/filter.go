@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterState holds the transport filter panel while it is open. chosen is
+// aligned with api.Transportations.
+type filterState struct {
+	chosen      []bool
+	cursor      int
+	returnState appState
+}
+
+// SetTransportations restricts the searches of this session to the given
+// transport types without changing the saved default. Empty means all types.
+func (m *Model) SetTransportations(types []string) {
+	m.transport = normalizeTransport(types)
+}
+
+// normalizeTransport returns nil when types select nothing or everything,
+// both of which mean no filter.
+func normalizeTransport(types []string) []string {
+	if len(types) == 0 || len(types) == len(api.Transportations) {
+		return nil
+	}
+	return append([]string{}, types...)
+}
+
+// transportLabel describes a transport filter, e.g. "train, bus".
+func transportLabel(types []string) string {
+	if len(types) == 0 {
+		return "all"
+	}
+	return strings.Join(types, ", ")
+}
+
+// transportNote is the line shown under result titles while a filter is
+// active.
+func (m *Model) transportNote() string {
+	if len(m.transport) == 0 {
+		return ""
+	}
+	return "Only: " + transportLabel(m.transport) + "\n"
+}
+
+// openFilter shows the transport filter panel preset with the active filter.
+func (m *Model) openFilter() {
+	chosen := make([]bool, len(api.Transportations))
+	for i, t := range api.Transportations {
+		for _, active := range m.transport {
+			if t == active {
+				chosen[i] = true
+			}
+		}
+	}
+	m.filter = filterState{chosen: chosen, returnState: m.state}
+	m.state = stateTransportFilter
+}
+
+// applyFilter activates and saves the chosen transport types as default and
+// repeats the search that was shown.
+func (m *Model) applyFilter() tea.Cmd {
+	var types []string
+	for i, ok := range m.filter.chosen {
+		if ok {
+			types = append(types, api.Transportations[i])
+		}
+	}
+	m.transport = normalizeTransport(types)
+	m.store.Preferences.Transportations = m.transport
+	m.status = ""
+	if err := m.store.Preferences.Save(); err != nil {
+		m.status = fmt.Sprintf("❌ Failed to save filter: %v", err)
+	}
+
+	m.state = m.filter.returnState
+	switch m.state {
+	case stateShowStationboard:
+		if len(m.boardStations) > 0 {
+			return m.reloadStationboard()
+		}
+	case stateShowConnections:
+		if m.fromStation != nil && m.toStation != nil {
+			return m.reloadConnections()
+		}
+	}
+	return nil
+}
+
+// filterView renders the transport filter panel.
+func (m *Model) filterView() string {
+	var s strings.Builder
+	s.WriteString("🚦 Transport types (saved as default):\n\n")
+	for i, t := range api.Transportations {
+		cursor := " "
+		if i == m.filter.cursor {
+			cursor = ">"
+		}
+		box := "[ ]"
+		if m.filter.chosen[i] {
+			box = "[x]"
+		}
+		s.WriteString(fmt.Sprintf("%s %s %s\n", cursor, box, strings.ToUpper(t[:1])+t[1:]))
+	}
+	s.WriteString("\nSelecting none or all shows every type.")
+	return s.String()
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTransportFilter(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &pagedProvider{FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata"))}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	m.SetStationboardLimit(3)
+	cmd := m.openFavorite(store.Favorite{Name: "Chur", Stations: []string{"Chur"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.state != stateTransportFilter {
+		t.Fatalf("expected filter panel, got state %v", m.state)
+	}
+	// select train and bus
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	m.Update(space)
+	for i := 0; i < 3; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m.Update(space)
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != stateLoadingConnections || cmd == nil {
+		t.Fatalf("expected the stationboard to be reloaded, got state %v", m.state)
+	}
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	want := []string{"train", "bus"}
+	if m.state != stateShowStationboard || !reflect.DeepEqual(m.transport, want) {
+		t.Fatalf("expected filtered board, got state %v and filter %v", m.state, m.transport)
+	}
+	if got := p.opts[len(p.opts)-1].Transportations; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected filter in the request, got %v", got)
+	}
+
+	// the filter is the default of the next session
+	st, err = store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewModel(api.NewClientWithProvider(p), st).transport; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected saved filter %v, got %v", want, got)
+	}
+}
+
+func TestTransportFilterCancel(t *testing.T) {
+	m := newFixtureModel(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != stateMenu || m.transport != nil {
+		t.Errorf("expected unchanged filter back in the menu, got state %v and filter %v", m.state, m.transport)
+	}
+}
+
+func TestNormalizeTransport(t *testing.T) {
+	if got := normalizeTransport(api.Transportations); got != nil {
+		t.Errorf("all types should mean no filter, got %v", got)
+	}
+	if got := normalizeTransport([]string{"ship"}); !reflect.DeepEqual(got, []string{"ship"}) {
+		t.Errorf("unexpected filter %v", got)
+	}
+}
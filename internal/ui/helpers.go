@@ -2,10 +2,13 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	api "SBBuddy/internal/api"
+	"SBBuddy/internal/export"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/lipgloss"
@@ -29,6 +32,96 @@ func countChanges(sections []api.Section) int {
 	return changes
 }
 
+// intermediateStops returns the stops a journey section passes between its
+// departure and arrival. The API includes both ends in the pass list.
+func intermediateStops(section api.Section) []api.Stop {
+	if section.Journey == nil {
+		return nil
+	}
+	stops := section.Journey.PassList
+	if len(stops) > 0 && sameStation(stops[0].Station, section.Departure.Station) {
+		stops = stops[1:]
+	}
+	if len(stops) > 0 && sameStation(stops[len(stops)-1].Station, section.Arrival.Station) {
+		stops = stops[:len(stops)-1]
+	}
+	return stops
+}
+
+// tripStops returns the stops a departure serves after the station of the
+// board, including its destination. For an arrival it returns the stops
+// before, starting at its origin.
+func tripStops(e *api.StationboardEntry, arrivals bool) []api.Stop {
+	if e == nil {
+		return nil
+	}
+	stops := e.PassList
+	atBoard := func(s api.Stop) bool {
+		return e.Stop.Station.Name == "" || sameStation(s.Station, e.Stop.Station)
+	}
+	if arrivals {
+		if len(stops) > 0 && atBoard(stops[len(stops)-1]) {
+			stops = stops[:len(stops)-1]
+		}
+		return stops
+	}
+	if len(stops) > 0 && atBoard(stops[0]) {
+		stops = stops[1:]
+	}
+	return stops
+}
+
+// boardTime returns the scheduled and expected time of a stationboard entry:
+// its arrival on arrival boards, its departure otherwise.
+func boardTime(sb *api.StationboardResponse, e api.StationboardEntry) (string, string) {
+	if sb != nil && sb.Arrivals {
+		return e.Stop.Arrival, e.Stop.Prognosis.Arrival
+	}
+	return e.Stop.Departure, e.Stop.Prognosis.Departure
+}
+
+// boardDirection renders within n cells where a train goes or, on arrival
+// boards, where it comes from.
+func boardDirection(sb *api.StationboardResponse, e api.StationboardEntry, n int) string {
+	if !sb.Arrivals {
+		return truncateRoute(sb.EntryStation(e).Name, e.To, n)
+	}
+	origin := e.Origin()
+	if origin == "" {
+		return "."
+	}
+	return truncate("from "+origin, n)
+}
+
+// directionTitle is the header of the direction column.
+func directionTitle(sb *api.StationboardResponse) string {
+	if sb != nil && sb.Arrivals {
+		return "Origin"
+	}
+	return "Direction"
+}
+
+func sameStation(a, b api.Location) bool {
+	if a.ID != "" && b.ID != "" {
+		return a.ID == b.ID
+	}
+	return a.Name == b.Name
+}
+
+// journeySection returns the index of the next journey section from start in
+// direction dir (+1 or -1), or start if there is none.
+func journeySection(conn *api.Connection, start, dir int) int {
+	if conn == nil {
+		return start
+	}
+	for i := start + dir; i >= 0 && i < len(conn.Sections); i += dir {
+		if conn.Sections[i].Journey != nil {
+			return i
+		}
+	}
+	return start
+}
+
 func formatChanges(changes int) string {
 	if changes == 0 {
 		return "Direct"
@@ -102,23 +195,9 @@ func formatISOTime(ts string) string {
 	return "--:--"
 }
 
-// parseAPITime attempts to parse timestamps returned by the API. It supports
-// both the standard RFC3339 format (with timezone colon) and the variant
-// without a colon in the timezone offset (e.g. "+0200").
+// parseAPITime attempts to parse timestamps returned by the API.
 func parseAPITime(v string) (time.Time, error) {
-	layouts := []string{
-		time.RFC3339,
-		"2006-01-02T15:04:05-0700",
-	}
-	var lastErr error
-	for _, l := range layouts {
-		if t, err := time.Parse(l, v); err == nil {
-			return t, nil
-		} else {
-			lastErr = err
-		}
-	}
-	return time.Time{}, lastErr
+	return api.ParseTime(v)
 }
 
 func durationBetween(start, end string) string {
@@ -133,36 +212,213 @@ func durationBetween(start, end string) string {
 	return fmt.Sprintf("%d:%02d", h, m)
 }
 
+var (
+	changedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	cancelledStyle = lipgloss.NewStyle().Strikethrough(true).Foreground(lipgloss.Color("241"))
+)
+
+// expectedTime formats a scheduled time followed by the expected one when the
+// prognosis differs, e.g. "12:30 → 12:33".
+func expectedTime(scheduled, expected string) string {
+	s := formatISOTime(scheduled)
+	if expected == "" {
+		return s
+	}
+	if e := formatISOTime(expected); e != s {
+		return s + " → " + e
+	}
+	return s
+}
+
+// stopPlatform returns the platform a stop is served from and whether it was
+// changed from the scheduled one.
+func stopPlatform(stop api.Stop) (string, bool) {
+	scheduled, expected := stop.Platform, stop.Prognosis.Platform
+	if scheduled == "" {
+		return expected, false
+	}
+	if expected != "" && expected != scheduled {
+		return expected, true
+	}
+	return scheduled, false
+}
+
+// platformLabel renders the platform of a stop with a highlighted note when
+// it changed, or "" when the platform is unknown.
+func platformLabel(stop api.Stop) string {
+	platform, changed := stopPlatform(stop)
+	if platform == "" {
+		return ""
+	}
+	if changed {
+		return changedStyle.Render(fmt.Sprintf("%s (was %s)", platform, stop.Platform))
+	}
+	return platform
+}
+
+// strike crosses out s with combining characters. Unlike an ANSI style this
+// survives inside bubbles tables, which measure and truncate cell text.
+func strike(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		sb.WriteRune(r)
+		if r != ' ' {
+			sb.WriteRune('\u0336')
+		}
+	}
+	return sb.String()
+}
+
+// connectionCancelled reports whether any leg of the connection is cancelled.
+func connectionCancelled(c api.Connection) bool {
+	for _, s := range c.Sections {
+		if s.Departure.Cancelled || s.Arrival.Cancelled {
+			return true
+		}
+	}
+	return false
+}
+
+// capacityFor picks the occupancy forecast of the given travel class.
+func capacityFor(first, second, class int) int {
+	if class == 1 {
+		return first
+	}
+	return second
+}
+
+// connectionCapacity returns the highest occupancy forecast across the legs
+// of a connection, falling back to the connection-wide forecast.
+func connectionCapacity(c api.Connection, class int) int {
+	level := 0
+	for _, s := range c.Sections {
+		if s.Journey != nil {
+			if l := capacityFor(s.Journey.Capacity1st, s.Journey.Capacity2nd, class); l > level {
+				level = l
+			}
+		}
+	}
+	if level == 0 {
+		level = capacityFor(c.Capacity1st, c.Capacity2nd, class)
+	}
+	return level
+}
+
+// formatCapacity renders an occupancy level (1 low to 3 high) as a gauge.
+// Unknown forecasts are shown as ".".
+func formatCapacity(level int) string {
+	switch {
+	case level <= 0:
+		return "."
+	case level == 1:
+		return "●○○"
+	case level == 2:
+		return "●●○"
+	default:
+		return "●●●"
+	}
+}
+
+// capacityLabel describes an occupancy level in words.
+func capacityLabel(level int) string {
+	switch {
+	case level <= 0:
+		return "unknown"
+	case level == 1:
+		return "low"
+	case level == 2:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
 func renderStationboardTable(sb *api.StationboardResponse) string {
+	return renderStationboardTableSized(sb, 0, 0)
+}
+
+// renderStationboardTableSized renders the stationboard for a terminal of the
+// given width, showing at most maxRows departures. Zero values keep the
+// natural size. Wide terminals get an operator column and the direction is
+// truncated to fit narrow ones.
+func renderStationboardTableSized(sb *api.StationboardResponse, width, maxRows int) string {
 	if sb == nil {
 		return ""
 	}
 
-	tbl := ltable.New().
-		Border(lipgloss.NormalBorder()).
-		Headers("Time", "Delay", "Train", "Direction", "Platform")
+	entries := sb.Stationboard
+	if maxRows > 0 && len(entries) > maxRows {
+		entries = entries[:maxRows]
+	}
 
-	for _, e := range sb.Stationboard {
-		platform := e.Stop.Platform
-		if platform == "" {
-			platform = e.Stop.Prognosis.Platform
-		}
+	// All columns but the direction, which takes the remaining width.
+	// Combined boards name the station of each departure after its time.
+	headers := []string{"Time", "Delay", "Train", "Platform", "Operator"}
+	if sb.Combined() {
+		headers = append([]string{"Time", "Station"}, headers[1:]...)
+	}
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		platform := platformLabel(e.Stop)
 		if platform == "" {
 			platform = "."
 		}
-
 		train := fmt.Sprintf("%s%s", e.Category, e.Number)
-		direction := fmt.Sprintf("%s → %s", sb.Station.Name, e.To)
+		scheduled, expected := boardTime(sb, e)
+		timeStr := expectedTime(scheduled, expected)
+		delay := formatDelay(e.Stop.Delay)
+		if e.Stop.Cancelled {
+			timeStr = cancelledStyle.Render(strike(formatISOTime(scheduled)))
+			train = cancelledStyle.Render(strike(train))
+			delay = "cancelled"
+		}
+		rows[i] = []string{timeStr, delay, train, platform, e.Operator}
+		if sb.Combined() {
+			rows[i] = append([]string{timeStr, sb.EntryStation(e).Name}, rows[i][1:]...)
+		}
+	}
 
-		tbl.Row(
-			formatISOTime(e.Stop.Departure),
-			formatDelay(e.Stop.Delay),
-			train,
-			direction,
-			platform,
-		)
+	colWidth := func(i int) int {
+		w := lipgloss.Width(headers[i])
+		for _, r := range rows {
+			w = max(w, lipgloss.Width(r[i]))
+		}
+		return w
+	}
+	cols := len(headers) - 1 // without operator
+	dirWidth := 0
+	if width > 0 {
+		fixed := func(n int) int {
+			w := n + 2 // borders around n+1 columns
+			for i := 0; i < n; i++ {
+				w += colWidth(i)
+			}
+			return w
+		}
+		if width-fixed(len(headers)) >= 30 {
+			cols = len(headers)
+		}
+		dirWidth = max(width-fixed(cols), 12)
 	}
 
+	// the direction goes right after the train
+	split := len(headers) - 2
+	withDirection := func(cells []string, direction string) []string {
+		out := append([]string{}, cells[:split]...)
+		out = append(out, direction)
+		return append(out, cells[split:cols]...)
+	}
+
+	tbl := ltable.New().
+		Border(lipgloss.NormalBorder()).
+		Headers(withDirection(headers, directionTitle(sb))...)
+	for i, e := range entries {
+		direction := boardDirection(sb, e, dirWidth)
+		if e.Stop.Cancelled {
+			direction = cancelledStyle.Render(direction)
+		}
+		tbl.Row(withDirection(rows[i], direction)...)
+	}
 	return tbl.String()
 }
 
@@ -171,26 +427,246 @@ func RenderStationboardTable(sb *api.StationboardResponse) string {
 	return renderStationboardTable(sb)
 }
 
+// buildStationboardTable builds the focusable stationboard table of the TUI.
+// The direction takes the width left by the other columns and an operator
+// column is added on wide terminals. Cells are plain text because the table
+// measures and truncates them. A non-nil live adds the countdown column of
+// the live mode, a non-nil walk the column telling when to leave.
+func buildStationboardTable(sb *api.StationboardResponse, width int, live *liveView, walk *walkView) table.Model {
+	columns := []table.Column{
+		{Title: "Time", Width: 13},
+		{Title: "Delay", Width: 9},
+		{Title: "Train", Width: 8},
+		{Title: directionTitle(sb), Width: 30},
+		{Title: "Platform", Width: 9},
+	}
+	dir := 3
+	if live != nil {
+		columns = append(columns[:1], append([]table.Column{{Title: "Due", Width: 12}}, columns[1:]...)...)
+		dir++
+	}
+	leave := dir - 2
+	if walk != nil {
+		columns = append(columns[:leave], append([]table.Column{{Title: "Leave", Width: 12}}, columns[leave:]...)...)
+		dir++
+	}
+	// combined boards name the station of each departure after its time
+	station := dir - 2
+	if sb.Combined() {
+		columns = append(columns[:station], append([]table.Column{{Title: "Station", Width: stationWidth}}, columns[station:]...)...)
+		dir++
+	}
+	operator := false
+	if width > 0 {
+		// every cell is padded by one space on each side
+		used := 0
+		for i, c := range columns {
+			if i != dir {
+				used += c.Width + 2
+			}
+		}
+		used += 2 // direction padding
+		if width-used-12 >= 30 {
+			operator = true
+			used += 12
+		}
+		columns[dir].Width = min(max(width-used, minRouteWidth), 50)
+	}
+	if operator {
+		columns = append(columns, table.Column{Title: "Operator", Width: 10})
+	}
+
+	var rows []table.Row
+	if sb != nil {
+		for _, e := range sb.Stationboard {
+			scheduled, expected := boardTime(sb, e)
+			timeStr := expectedTime(scheduled, expected)
+			delay := formatDelay(e.Stop.Delay)
+			train := fmt.Sprintf("%s%s", e.Category, e.Number)
+			if e.Stop.Cancelled {
+				timeStr = strike(formatISOTime(scheduled))
+				train = strike(train)
+				delay = "cancelled"
+			}
+			platform, changed := stopPlatform(e.Stop)
+			if platform == "" {
+				platform = "."
+			} else if changed {
+				platform = fmt.Sprintf("%s (was %s)", platform, e.Stop.Platform)
+			}
+			row := table.Row{
+				timeStr,
+				delay,
+				train,
+				boardDirection(sb, e, columns[dir].Width),
+				platform,
+			}
+			if live != nil {
+				row = append(row[:1], append(table.Row{live.due(sb, e)}, row[1:]...)...)
+			}
+			if walk != nil {
+				row = append(row[:leave], append(table.Row{walk.leave(sb, e)}, row[leave:]...)...)
+			}
+			if sb.Combined() {
+				row = append(row[:station], append(table.Row{truncate(sb.EntryStation(e).Name, stationWidth)}, row[station:]...)...)
+			}
+			if operator {
+				row = append(row, truncate(e.Operator, 10))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(len(rows)+1),
+	)
+	t.SetStyles(table.DefaultStyles())
+	return t
+}
+
+// mergeDepartures appends the departures of a later page that are not
+// already listed. Pages overlap because they start at the last known
+// departure time.
+func mergeDepartures(entries, more []api.StationboardEntry) ([]api.StationboardEntry, int) {
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[departureKey(e)] = true
+	}
+	added := 0
+	for _, e := range more {
+		k := departureKey(e)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		entries = append(entries, e)
+		added++
+	}
+	return entries, added
+}
+
+// departureKey identifies a departure across stationboard pages.
+func departureKey(e api.StationboardEntry) string {
+	return strings.Join([]string{e.Stop.Departure, e.Stop.Arrival, e.Category, e.Number, e.Name, e.To}, "|")
+}
+
 // RenderConnectionsTable exposes connection table rendering for CLI usage.
+// Occupancy is shown for 2nd class.
 func RenderConnectionsTable(cr *api.ConnectionsResponse) string {
-	tbl := buildConnectionsTable(cr)
+	return RenderConnectionsTableForClass(cr, 2)
+}
+
+// RenderConnectionsTableForClass renders the connection table with the
+// occupancy forecast of the given travel class (1 or 2).
+func RenderConnectionsTableForClass(cr *api.ConnectionsResponse, class int) string {
+	tbl := buildConnectionsTable(cr, class, 0)
 	return tbl.View()
 }
 
-func buildConnectionsTable(cr *api.ConnectionsResponse) table.Model {
-	cols := []table.Column{
-		{Title: "Departure", Width: 9},
-		{Title: "Arrival", Width: 9},
-		{Title: "Delay", Width: 6},
-		{Title: "Duration", Width: 8},
-		{Title: "Changes", Width: 8},
-		{Title: "From → To", Width: 25},
+// connColumn describes a column of the connections table.
+type connColumn struct {
+	title string
+	width int
+	cell  func(c api.Connection) string
+}
+
+// optionalConnColumns are added, in this order, when the terminal is wide
+// enough.
+var optionalConnColumns = []connColumn{
+	{"Platform", 8, connectionPlatform},
+	{"Category", 10, connectionCategories},
+	{"Operator", 12, connectionOperators},
+}
+
+// connectionPlatform returns the departure platform of the first leg.
+func connectionPlatform(c api.Connection) string {
+	for _, s := range c.Sections {
+		if s.Journey != nil {
+			if p, _ := stopPlatform(s.Departure); p != "" {
+				return p
+			}
+			break
+		}
+	}
+	return "."
+}
+
+// connectionCategories lists the train categories of all legs, e.g. "IC/S".
+func connectionCategories(c api.Connection) string {
+	var cats []string
+	for _, s := range c.Sections {
+		if s.Journey != nil && s.Journey.Category != "" {
+			cats = append(cats, s.Journey.Category)
+		}
+	}
+	return strings.Join(cats, "/")
+}
+
+// connectionOperators lists the distinct operators of all legs.
+func connectionOperators(c api.Connection) string {
+	var ops []string
+	seen := map[string]bool{}
+	for _, s := range c.Sections {
+		if s.Journey != nil && s.Journey.Operator != "" && !seen[s.Journey.Operator] {
+			seen[s.Journey.Operator] = true
+			ops = append(ops, s.Journey.Operator)
+		}
+	}
+	return strings.Join(ops, "/")
+}
+
+// buildConnectionsTable lays the connections out for a terminal of the given
+// width. Width 0 keeps the classic fixed layout; wider terminals get extra
+// columns and the route column takes the remaining space.
+func buildConnectionsTable(cr *api.ConnectionsResponse, class int, width int) table.Model {
+	cols := []connColumn{
+		{"Departure", 13, nil},
+		{"Arrival", 13, nil},
+		{"Delay", 9, nil},
+		{"Duration", 8, nil},
+		{"Changes", 11, nil},
+		{"Load", 4, nil},
+	}
+	routeWidth := 25
+	if width > 0 {
+		// every cell is padded by one space on each side
+		used := 0
+		for _, c := range cols {
+			used += c.width + 2
+		}
+		used += 2 // route column padding
+		for _, c := range optionalConnColumns {
+			if used+c.width+2+minRouteWidth+10 > width {
+				break
+			}
+			cols = append(cols, c)
+			used += c.width + 2
+		}
+		routeWidth = width - used
+		if routeWidth < minRouteWidth {
+			routeWidth = minRouteWidth
+		}
+		if routeWidth > 50 {
+			routeWidth = 50
+		}
+	}
+
+	var columns []table.Column
+	for _, c := range cols {
+		columns = append(columns, table.Column{Title: c.title, Width: c.width})
 	}
+	columns = append(columns, table.Column{Title: "From → To", Width: routeWidth})
 
 	var rows []table.Row
 	if cr != nil {
 		for _, c := range cr.Connections {
 			fromTo := fmt.Sprintf("%s → %s", c.From.Station.Name, c.To.Station.Name)
+			if width > 0 {
+				fromTo = truncateRoute(c.From.Station.Name, c.To.Station.Name, routeWidth)
+			}
 
 			duration := c.Duration
 			if duration == "" {
@@ -201,25 +677,40 @@ func buildConnectionsTable(cr *api.ConnectionsResponse) table.Model {
 
 			changes := countChanges(c.Sections)
 			changesStr := formatChanges(changes)
+			if t, ok := connectionRisk(&c); ok {
+				changesStr += " " + riskMarker(t.risk)
+			}
 
 			delayStr := ""
 			if c.From.Delay > 0 {
 				delayStr = formatDelay(c.From.Delay)
 			}
 
-			rows = append(rows, table.Row{
-				formatISOTime(c.From.Departure),
-				formatISOTime(c.To.Arrival),
+			dep := expectedTime(c.From.Departure, c.From.Prognosis.Departure)
+			arr := expectedTime(c.To.Arrival, c.To.Prognosis.Arrival)
+			if connectionCancelled(c) {
+				dep = strike(formatISOTime(c.From.Departure))
+				arr = strike(formatISOTime(c.To.Arrival))
+				delayStr = "cancelled"
+			}
+
+			row := table.Row{
+				dep,
+				arr,
 				delayStr,
 				duration,
 				changesStr,
-				fromTo,
-			})
+				formatCapacity(connectionCapacity(c, class)),
+			}
+			for _, col := range cols[len(row):] {
+				row = append(row, truncate(col.cell(c), col.width))
+			}
+			rows = append(rows, append(row, fromTo))
 		}
 	}
 
 	t := table.New(
-		table.WithColumns(cols),
+		table.WithColumns(columns),
 		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(len(rows)+1),
@@ -314,12 +805,9 @@ func FormatDateDisplay(apiDate string) string {
 	return apiDate
 }
 
-// tailUntilClosestArrival returns up to five connections ending with the
-// connection whose arrival time is nearest to t.
-func tailUntilClosestArrival(conns []api.Connection, t time.Time) []api.Connection {
-	if len(conns) <= 5 {
-		return conns
-	}
+// closestArrival returns the index of the connection whose arrival time is
+// nearest to t, or -1 if no arrival time is known.
+func closestArrival(conns []api.Connection, t time.Time) int {
 	idx := -1
 	minDiff := time.Duration(1<<63 - 1)
 	for i, c := range conns {
@@ -336,18 +824,7 @@ func tailUntilClosestArrival(conns []api.Connection, t time.Time) []api.Connecti
 			idx = i
 		}
 	}
-	if idx == -1 {
-		return conns[:min(len(conns), 5)]
-	}
-	end := idx + 1
-	if end > len(conns) {
-		end = len(conns)
-	}
-	start := end - 5
-	if start < 0 {
-		start = 0
-	}
-	return conns[start:end]
+	return idx
 }
 
 func absDuration(d time.Duration) time.Duration {
@@ -385,19 +862,105 @@ func lastConnectionTime(cr *api.ConnectionsResponse, arrival bool) (time.Time, e
 	return parseAPITime(ts)
 }
 
-// firstStationboardTime returns the departure time of the first stationboard entry.
+// firstStationboardTime returns the departure (or arrival) time of the first
+// stationboard entry.
 func firstStationboardTime(sb *api.StationboardResponse) (time.Time, error) {
 	if sb == nil || len(sb.Stationboard) == 0 {
 		return time.Time{}, fmt.Errorf("no stationboard")
 	}
-	return parseAPITime(sb.Stationboard[0].Stop.Departure)
+	scheduled, _ := boardTime(sb, sb.Stationboard[0])
+	return parseAPITime(scheduled)
 }
 
-// lastStationboardTime returns the departure time of the last stationboard entry.
+// lastStationboardTime returns the departure (or arrival) time of the last
+// stationboard entry.
 func lastStationboardTime(sb *api.StationboardResponse) (time.Time, error) {
 	if sb == nil || len(sb.Stationboard) == 0 {
 		return time.Time{}, fmt.Errorf("no stationboard")
 	}
-	last := sb.Stationboard[len(sb.Stationboard)-1]
-	return parseAPITime(last.Stop.Departure)
+	scheduled, _ := boardTime(sb, sb.Stationboard[len(sb.Stationboard)-1])
+	return parseAPITime(scheduled)
+}
+
+// icsFileName builds a file name like "SBBuddy_Chur_Zurich-HB_20240101-1230.ics"
+// for a connection export.
+func icsFileName(conn *api.Connection) string {
+	clean := func(s string) string {
+		var b strings.Builder
+		for _, r := range s {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+				b.WriteRune(r)
+			case r == ' ' || r == '-' || r == ',' || r == '.':
+				b.WriteRune('-')
+			default:
+				if folded, ok := umlauts[r]; ok {
+					b.WriteString(folded)
+				}
+			}
+		}
+		return strings.Trim(b.String(), "-")
+	}
+	stamp := "connection"
+	if t, err := parseAPITime(conn.From.Departure); err == nil {
+		stamp = t.Local().Format("20060102-1504")
+	}
+	return fmt.Sprintf("SBBuddy_%s_%s_%s.ics", clean(conn.From.Station.Name), clean(conn.To.Station.Name), stamp)
+}
+
+var umlauts = map[rune]string{
+	'ä': "ae", 'ö': "oe", 'ü': "ue", 'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue",
+	'é': "e", 'è': "e", 'ê': "e", 'à': "a", 'â': "a", 'ç': "c", 'ô': "o",
+}
+
+// exportConnectionICS writes the connection as an iCalendar file into dir and
+// returns the written path.
+func exportConnectionICS(conn *api.Connection, dir string) (string, error) {
+	if conn == nil {
+		return "", fmt.Errorf("no connection selected")
+	}
+	path := filepath.Join(dir, icsFileName(conn))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := export.WriteICS(f, conn, time.Now()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// fuzzyMatch filters candidates by query, keeping their order within each
+// rank: prefix matches first, then substring matches, then matches where the
+// query letters merely appear in order. An empty query matches everything.
+func fuzzyMatch(candidates []string, query string) []string {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return append([]string{}, candidates...)
+	}
+	var prefix, substr, subseq []string
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		switch {
+		case strings.HasPrefix(lc, q):
+			prefix = append(prefix, c)
+		case strings.Contains(lc, q):
+			substr = append(substr, c)
+		case isSubsequence(q, lc):
+			subseq = append(subseq, c)
+		}
+	}
+	return append(append(prefix, substr...), subseq...)
+}
+
+func isSubsequence(needle, haystack string) bool {
+	n := []rune(needle)
+	i := 0
+	for _, r := range haystack {
+		if i < len(n) && r == n[i] {
+			i++
+		}
+	}
+	return i == len(n)
 }
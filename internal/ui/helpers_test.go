@@ -135,7 +135,7 @@ func TestRenderStationboardTableNoPlatform(t *testing.T) {
 	}
 }
 
-func TestTailUntilClosestArrival(t *testing.T) {
+func TestClosestArrival(t *testing.T) {
 	var cr api.ConnectionsResponse
 	data, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "connections.json"))
 	if err != nil {
@@ -148,13 +148,8 @@ func TestTailUntilClosestArrival(t *testing.T) {
 		t.Skip("not enough test connections")
 	}
 	t0, _ := time.Parse(time.RFC3339, cr.Connections[2].To.Arrival)
-	res := tailUntilClosestArrival(cr.Connections, t0)
-	if len(res) != 5 {
-		t.Errorf("expected 5 results, got %d", len(res))
-	}
-	last := res[len(res)-1]
-	if last.To.Arrival != cr.Connections[2].To.Arrival {
-		t.Errorf("expected last arrival %s got %s", cr.Connections[2].To.Arrival, last.To.Arrival)
+	if idx := closestArrival(cr.Connections, t0); idx != 2 {
+		t.Errorf("expected connection 2 to arrive closest, got %d", idx)
 	}
 }
 
@@ -206,3 +201,155 @@ func TestFirstLastTimeHelpers(t *testing.T) {
 		t.Errorf("expected same first and last stationboard time, got %v and %v", sFirst, sLast)
 	}
 }
+
+func TestExportConnectionICS(t *testing.T) {
+	conn := loadConn(t)
+	name := icsFileName(conn)
+	if !strings.HasPrefix(name, "SBBuddy_Chur_Zuerich-Altstetten_") || !strings.HasSuffix(name, ".ics") {
+		t.Errorf("unexpected file name %s", name)
+	}
+	path, err := exportConnectionICS(conn, t.TempDir())
+	if err != nil {
+		t.Fatalf("exportConnectionICS error: %v", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if strings.Count(string(data), "BEGIN:VEVENT") != 2 {
+		t.Errorf("expected one event per section")
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	candidates := []string{"Bern", "Zürich HB", "Basel SBB", "Zürich Altstetten", "Zug"}
+	got := fuzzyMatch(candidates, "zhb")
+	if len(got) != 1 || got[0] != "Zürich HB" {
+		t.Errorf("unexpected subsequence matches %v", got)
+	}
+	got = fuzzyMatch(candidates, "z")
+	if len(got) != 3 || got[0] != "Zürich HB" || got[2] != "Zug" {
+		t.Errorf("unexpected prefix matches %v", got)
+	}
+	got = fuzzyMatch(candidates, "sbb")
+	if len(got) != 1 || got[0] != "Basel SBB" {
+		t.Errorf("unexpected substring matches %v", got)
+	}
+	if len(fuzzyMatch(candidates, "")) != len(candidates) {
+		t.Errorf("empty query should match everything")
+	}
+}
+
+func TestIntermediateStops(t *testing.T) {
+	section := api.Section{
+		Journey: &api.Journey{PassList: []api.Stop{
+			{Station: api.Location{ID: "1", Name: "A"}},
+			{Station: api.Location{ID: "2", Name: "B"}},
+			{Station: api.Location{ID: "3", Name: "C"}},
+		}},
+		Departure: api.Stop{Station: api.Location{ID: "1", Name: "A"}},
+		Arrival:   api.Stop{Station: api.Location{ID: "3", Name: "C"}},
+	}
+	stops := intermediateStops(section)
+	if len(stops) != 1 || stops[0].Station.Name != "B" {
+		t.Errorf("unexpected stops %+v", stops)
+	}
+	if intermediateStops(api.Section{Walk: &api.Walk{}}) != nil {
+		t.Errorf("walks have no stops")
+	}
+}
+
+func TestJourneySection(t *testing.T) {
+	conn := &api.Connection{Sections: []api.Section{
+		{Walk: &api.Walk{}}, {Journey: &api.Journey{}}, {Walk: &api.Walk{}}, {Journey: &api.Journey{}},
+	}}
+	if got := journeySection(conn, -1, 1); got != 1 {
+		t.Errorf("first journey: got %d", got)
+	}
+	if got := journeySection(conn, 1, 1); got != 3 {
+		t.Errorf("next journey: got %d", got)
+	}
+	if got := journeySection(conn, 3, 1); got != 3 {
+		t.Errorf("stays on last journey: got %d", got)
+	}
+}
+
+func TestPrognosisHelpers(t *testing.T) {
+	if got := expectedTime("2024-01-01T12:30:00+01:00", "2024-01-01T12:33:00+01:00"); got != "12:30 → 12:33" {
+		t.Errorf("expectedTime with delay: %q", got)
+	}
+	if got := expectedTime("2024-01-01T12:30:00+01:00", "2024-01-01T12:30:00+01:00"); got != "12:30" {
+		t.Errorf("expectedTime on time: %q", got)
+	}
+	if p, changed := stopPlatform(api.Stop{Platform: "2", Prognosis: api.Prognosis{Platform: "3"}}); p != "3" || !changed {
+		t.Errorf("expected platform change, got %q %v", p, changed)
+	}
+	if p, changed := stopPlatform(api.Stop{Prognosis: api.Prognosis{Platform: "3"}}); p != "3" || changed {
+		t.Errorf("prognosis only is not a change, got %q %v", p, changed)
+	}
+	if got := strike("IC 3"); got != "I̶C̶ 3̶" {
+		t.Errorf("strike: %q", got)
+	}
+}
+
+func TestRenderTablesCancelled(t *testing.T) {
+	var sb api.StationboardResponse
+	data, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "stationboard.json"))
+	if err != nil {
+		t.Fatalf("read stationboard.json: %v", err)
+	}
+	if err := json.Unmarshal(data, &sb); err != nil {
+		t.Fatalf("unmarshal sb: %v", err)
+	}
+	sb.Stationboard[0].Stop.Cancelled = true
+	if out := renderStationboardTable(&sb); !strings.Contains(out, "cancelled") {
+		t.Errorf("expected cancelled marker:\n%s", out)
+	}
+
+	conn := loadConn(t)
+	conn.From.Prognosis.Departure = "2024-01-01T12:34:00+01:00"
+	out := RenderConnectionsTable(&api.ConnectionsResponse{Connections: []api.Connection{*conn}})
+	if !strings.Contains(out, "12:30 → 12:34") {
+		t.Errorf("expected scheduled and expected departure:\n%s", out)
+	}
+	conn.Sections[1].Arrival.Cancelled = true
+	out = RenderConnectionsTable(&api.ConnectionsResponse{Connections: []api.Connection{*conn}})
+	if !strings.Contains(out, "cancelled") || !strings.Contains(out, strike("12:30")) {
+		t.Errorf("expected struck out connection:\n%s", out)
+	}
+}
+
+func TestConnectionDetailsExpectedTimes(t *testing.T) {
+	conn := loadConn(t)
+	conn.From.Prognosis.Departure = "2024-01-01T12:34:00+01:00"
+	conn.Sections[0].Departure.Prognosis.Departure = "2024-01-01T12:34:00+01:00"
+	conn.To.Prognosis.Arrival = "2024-01-01T14:25:00+01:00"
+	details := renderConnectionDetailsLayout(conn, detailsLayout{cursor: -1})
+	if !strings.Contains(details, "🕐 12:30 → 12:34 → 14:20 → 14:25") {
+		t.Errorf("expected the expected times in the summary:\n%s", details)
+	}
+	if !strings.Contains(details, "Depart: 12:30 → 12:34") {
+		t.Errorf("expected the expected departure of the section:\n%s", details)
+	}
+}
+
+func TestConnectionCapacity(t *testing.T) {
+	conn := loadConn(t)
+	conn.Sections[0].Journey.Capacity1st = 1
+	conn.Sections[0].Journey.Capacity2nd = 2
+	conn.Sections[1].Journey.Capacity2nd = 3
+	if got := connectionCapacity(*conn, 2); got != 3 {
+		t.Errorf("2nd class: got %d", got)
+	}
+	if got := connectionCapacity(*conn, 1); got != 1 {
+		t.Errorf("1st class: got %d", got)
+	}
+	out := RenderConnectionsTableForClass(&api.ConnectionsResponse{Connections: []api.Connection{*conn}}, 2)
+	if !strings.Contains(out, "●●●") {
+		t.Errorf("expected capacity gauge:\n%s", out)
+	}
+	details := renderConnectionDetailsLayout(conn, detailsLayout{cursor: 0, class: 1})
+	if !strings.Contains(details, "Occupancy: ●○○ low (class 1)") {
+		t.Errorf("expected per-leg occupancy:\n%s", details)
+	}
+}
@@ -18,18 +18,33 @@ type KeyMap struct {
 	AddVia   key.Binding
 	DelVia   key.Binding
 	Modify   key.Binding
+	Export   key.Binding
+	Stops    key.Binding
+	Connect  key.Binding
+	Arrivals key.Binding
+	Live     key.Binding
+	Filter   key.Binding
+	Toggle   key.Binding
+
+	Favorite    key.Binding
+	DelFavorite key.Binding
+	RecallOlder key.Binding
+	RecallNewer key.Binding
+
+	NextSuggestion key.Binding
+	PrevSuggestion key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Quit, k.Back, k.Left, k.Right, k.Up, k.Down, k.Enter, k.Refresh, k.DateTime, k.Now, k.AddVia, k.DelVia, k.Modify, k.QR}
+	return []key.Binding{k.Quit, k.Back, k.Left, k.Right, k.Up, k.Down, k.Enter, k.Refresh, k.DateTime, k.Now, k.AddVia, k.DelVia, k.Modify, k.QR, k.Export, k.Stops, k.Connect, k.Arrivals, k.Live, k.Filter, k.Toggle, k.Favorite, k.DelFavorite, k.RecallOlder, k.RecallNewer, k.NextSuggestion, k.PrevSuggestion}
 }
 
 // FullHelp returns keybindings for the expanded help view.
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Quit, k.Back, k.Refresh, k.DateTime, k.Now, k.AddVia, k.DelVia, k.Modify, k.QR},
-		{k.Left, k.Right, k.Up, k.Down, k.Enter},
+		{k.Quit, k.Back, k.Refresh, k.DateTime, k.Now, k.AddVia, k.DelVia, k.Modify, k.QR, k.Export, k.Stops, k.Connect, k.Arrivals, k.Live, k.Filter, k.Toggle, k.Favorite, k.DelFavorite},
+		{k.Left, k.Right, k.Up, k.Down, k.Enter, k.RecallOlder, k.RecallNewer, k.NextSuggestion, k.PrevSuggestion},
 	}
 }
 
@@ -92,5 +107,57 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "modify"),
 		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export .ics"),
+		),
+		Stops: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter", "toggle stops"),
+		),
+		Connect: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "connections to stop"),
+		),
+		Arrivals: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "show arrivals"),
+		),
+		Live: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "live mode"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "transport filter"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "toggle"),
+		),
+		Favorite: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "save favorite"),
+		),
+		DelFavorite: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "delete favorite"),
+		),
+		RecallOlder: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "older recent station"),
+		),
+		RecallNewer: key.NewBinding(
+			key.WithKeys("ctrl+n"),
+			key.WithHelp("ctrl+n", "newer recent station"),
+		),
+		NextSuggestion: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next suggestion"),
+		),
+		PrevSuggestion: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("shift+tab", "prev suggestion"),
+		),
 	}
 }
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// defaultRulerWidth is used for separators until the terminal size is known.
+	defaultRulerWidth = 59
+	// maxInputWidth caps text inputs on wide terminals.
+	maxInputWidth = 40
+	// minRouteWidth is the narrowest "From → To" column worth showing.
+	minRouteWidth = 15
+	// stationWidth is the station column of combined stationboards.
+	stationWidth = 16
+)
+
+// truncate shortens s to at most n cells, marking the cut with "…".
+func truncate(s string, n int) string {
+	if n <= 0 || lipgloss.Width(s) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	var sb strings.Builder
+	w := 0
+	for _, r := range s {
+		rw := lipgloss.Width(string(r))
+		if w+rw > n-1 {
+			break
+		}
+		sb.WriteRune(r)
+		w += rw
+	}
+	return sb.String() + "…"
+}
+
+// truncateRoute renders "from → to" within n cells. The longer name is
+// shortened first so both ends stay recognizable.
+func truncateRoute(from, to string, n int) string {
+	route := from + " → " + to
+	if n <= 0 || lipgloss.Width(route) <= n {
+		return route
+	}
+	avail := n - lipgloss.Width(" → ")
+	fw, tw := lipgloss.Width(from), lipgloss.Width(to)
+	for fw+tw > avail && (fw > 1 || tw > 1) {
+		if fw >= tw {
+			fw--
+		} else {
+			tw--
+		}
+	}
+	return truncate(from, fw) + " → " + truncate(to, tw)
+}
+
+// ruler returns a horizontal separator spanning the terminal width.
+func ruler(ch string, width int) string {
+	if width <= 0 {
+		width = defaultRulerWidth
+	}
+	return strings.Repeat(ch, width)
+}
+
+// inputWidth returns the width of text inputs for the current terminal.
+func (m *Model) inputWidth() int {
+	if m.width <= 0 {
+		return maxInputWidth
+	}
+	// leave room for labels like "Via 1: " and the prompt
+	w := m.width - 12
+	if w > maxInputWidth {
+		w = maxInputWidth
+	}
+	if w < 10 {
+		w = 10
+	}
+	return w
+}
+
+// tableHeight returns how many rows a table may use below a header of the
+// given number of lines, or n when the terminal height is unknown.
+func (m *Model) tableHeight(n, header int) int {
+	if m.height <= 0 {
+		return n
+	}
+	// header, table header row and help line
+	h := m.height - header - 4
+	if h < 3 {
+		h = 3
+	}
+	if n < h {
+		return n
+	}
+	return h
+}
+
+// resize reflows the inputs and tables to the current terminal size.
+func (m *Model) resize() {
+	w := m.inputWidth()
+	for _, in := range []*textinput.Model{&m.stationInput, &m.fromInput, &m.toInput, &m.favoriteInput} {
+		in.Width = w
+	}
+	for i := range m.viaInputs {
+		m.viaInputs[i].Width = w
+	}
+	if m.connections != nil {
+		cursor := m.connTable.Cursor()
+		m.connTable = m.buildConnTable()
+		m.connTable.SetCursor(cursor)
+	}
+	if m.stationboard != nil {
+		cursor := m.sbTable.Cursor()
+		m.sbTable = m.buildSbTable()
+		m.sbTable.SetCursor(cursor)
+	}
+}
+
+// buildConnTable builds the connections table sized for the terminal.
+func (m *Model) buildConnTable() table.Model {
+	t := buildConnectionsTable(m.connections, m.travelClass, m.width)
+	header := 6 + len(m.viaStations)
+	rows := 0
+	if m.connections != nil {
+		rows = len(m.connections.Connections)
+	}
+	t.SetHeight(m.tableHeight(rows, header) + 1)
+	t.Focus()
+	return t
+}
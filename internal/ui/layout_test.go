@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("Zürich Altstetten", 8); got != "Zürich …" {
+		t.Errorf("truncate: %q", got)
+	}
+	if got := truncate("Bern", 8); got != "Bern" {
+		t.Errorf("short names stay: %q", got)
+	}
+	got := truncateRoute("Lausanne, Riponne-M. Béjart", "Bern", 20)
+	if lipgloss.Width(got) > 20 || !strings.HasSuffix(got, " → Bern") {
+		t.Errorf("truncateRoute should shorten the longer name: %q", got)
+	}
+}
+
+func TestWindowSizeReflowsConnections(t *testing.T) {
+	conn := loadConn(t)
+	m := InitialModel()
+	m.connections = &api.ConnectionsResponse{Connections: []api.Connection{*conn}}
+	m.connTable = m.buildConnTable()
+	m.state = stateShowConnections
+
+	m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	if got := len(m.connTable.Columns()); got != 10 {
+		t.Errorf("expected optional columns on a wide terminal, got %d", got)
+	}
+	if !strings.Contains(m.View(), "IC/S") {
+		t.Errorf("expected category column in view")
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 90, Height: 40})
+	if got := len(m.connTable.Columns()); got != 7 {
+		t.Errorf("expected base columns on a narrow terminal, got %d", got)
+	}
+	if m.fromInput.Width != maxInputWidth {
+		t.Errorf("input width %d", m.fromInput.Width)
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 30, Height: 10})
+	if m.fromInput.Width != 18 {
+		t.Errorf("input should shrink, got %d", m.fromInput.Width)
+	}
+}
+
+func TestStationboardFitsWidth(t *testing.T) {
+	sb := &api.StationboardResponse{
+		Station: api.Location{Name: "Zürich HB"},
+		Stationboard: []api.StationboardEntry{
+			{Category: "IC", Number: "5", Operator: "SBB", To: "Lausanne, Riponne-M. Béjart"},
+		},
+	}
+	for _, line := range strings.Split(renderStationboardTableSized(sb, 50, 0), "\n") {
+		if w := lipgloss.Width(line); w > 50 {
+			t.Errorf("line exceeds width (%d): %q", w, line)
+		}
+	}
+	if !strings.Contains(renderStationboardTableSized(sb, 120, 0), "Operator") {
+		t.Errorf("expected operator column on a wide terminal")
+	}
+}
@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// DefaultLiveInterval is how often a live stationboard is fetched again.
+	DefaultLiveInterval = 30 * time.Second
+	// liveTick is how often countdowns are updated.
+	liveTick = time.Second
+	// flashFor is how long a departure flashes after its delay or platform
+	// changed.
+	flashFor = 6 * time.Second
+)
+
+// liveTickMsg drives the live stationboard. seq identifies the live session
+// that scheduled it so ticks of a stopped session end their loop.
+type liveTickMsg struct {
+	seq int
+}
+
+// liveBoardMsg carries a stationboard fetched by the live mode.
+type liveBoardMsg struct {
+	id  int
+	sb  *api.StationboardResponse
+	err error
+}
+
+// liveState tracks the live mode of the stationboard.
+type liveState struct {
+	on         bool
+	seq        int
+	refreshing bool
+	updated    time.Time            // when the board was last fetched
+	flash      map[string]time.Time // departureKey → end of the flash
+}
+
+// liveView is what buildStationboardTable needs to render the live mode.
+type liveView struct {
+	now   time.Time
+	flash map[string]time.Time
+}
+
+// due renders the countdown of a departure, marked on every other second
+// while it flashes.
+func (l *liveView) due(sb *api.StationboardResponse, e api.StationboardEntry) string {
+	s := "-"
+	if t, err := expectedBoardTime(sb, e); err == nil {
+		s = countdown(t, l.now)
+	}
+	if e.Stop.Cancelled {
+		s = "-"
+	}
+	if l.now.Before(l.flash[departureKey(e)]) && l.now.Second()%2 == 0 {
+		s = "● " + s
+	}
+	return s
+}
+
+// SetLiveInterval sets how often a live stationboard is fetched again.
+// Values below ten seconds are ignored.
+func (m *Model) SetLiveInterval(d time.Duration) {
+	if d >= 10*time.Second {
+		m.liveInterval = d
+	}
+}
+
+// toggleLive switches the live mode of the stationboard. Starting it shows
+// the departures from now on.
+func (m *Model) toggleLive() tea.Cmd {
+	if m.live.on {
+		m.stopLive()
+		m.sbTable = m.buildSbTable()
+		return nil
+	}
+	m.live = liveState{on: true, seq: m.live.seq + 1}
+	return tea.Batch(m.refreshLive(), m.liveTickCmd())
+}
+
+// stopLive ends the live mode; pending ticks are ignored.
+func (m *Model) stopLive() {
+	m.live = liveState{seq: m.live.seq + 1}
+}
+
+func (m *Model) liveTickCmd() tea.Cmd {
+	seq := m.live.seq
+	return tea.Tick(liveTick, func(time.Time) tea.Msg { return liveTickMsg{seq: seq} })
+}
+
+// handleLiveTick updates the countdowns, drops departed trains and fetches
+// the board again once the interval has passed.
+func (m *Model) handleLiveTick(msg liveTickMsg) tea.Cmd {
+	if !m.live.on || msg.seq != m.live.seq {
+		return nil
+	}
+	if m.state != stateShowStationboard || m.stationboard == nil {
+		return m.liveTickCmd()
+	}
+	m.dropDeparted()
+	m.dropUnreachable()
+	m.rebuildSbTable()
+	if m.now().Sub(m.live.updated) >= m.liveInterval {
+		return tea.Batch(m.refreshLive(), m.liveTickCmd())
+	}
+	return m.liveTickCmd()
+}
+
+// refreshLive fetches the board from now on without leaving the view. It
+// keeps as many departures as are listed, at least one page.
+func (m *Model) refreshLive() tea.Cmd {
+	if m.live.refreshing || m.paging.loading || len(m.boardStations) == 0 {
+		return nil
+	}
+	opts := m.stationboardOptions()
+	if m.stationboard != nil {
+		opts.Limit = min(max(opts.Limit, len(m.stationboard.Stationboard)), 100)
+	}
+	m.live.refreshing = true
+	m.live.updated = m.now()
+	ctx, id := m.startFetch()
+	// the board is fetched again more often than the cache expires
+	ctx = api.RefreshCache(ctx)
+	client, stations := m.api, m.boardStations
+	date, tm := m.walkStart()
+	return func() tea.Msg {
+		sb, err := client.FetchStationboards(ctx, stations, date, tm, opts)
+		return liveBoardMsg{id: id, sb: sb, err: err}
+	}
+}
+
+// handleLiveBoard replaces the board with a live refresh and flashes the
+// departures whose delay or platform changed.
+func (m *Model) handleLiveBoard(msg liveBoardMsg) {
+	m.live.refreshing = false
+	if !m.finishFetch(msg.id) || !m.live.on {
+		return
+	}
+	if msg.err != nil {
+		m.status = fmt.Sprintf("❌ live update failed: %v", msg.err)
+		return
+	}
+	m.status = ""
+	now := m.now()
+	if m.stationboard != nil && msg.sb != nil {
+		if m.live.flash == nil {
+			m.live.flash = make(map[string]time.Time)
+		}
+		for _, k := range changedDepartures(m.stationboard.Stationboard, msg.sb.Stationboard) {
+			m.live.flash[k] = now.Add(flashFor)
+		}
+	}
+	m.lastSearchTime = now.Truncate(time.Minute)
+	m.lastSearchArrival = false
+	m.stationboard = msg.sb
+	m.paging = pagingState{}
+	m.dropDeparted()
+	m.dropUnreachable()
+	m.rebuildSbTable()
+}
+
+// rebuildSbTable rebuilds the stationboard table keeping the cursor on the
+// same departure if it is still listed.
+func (m *Model) rebuildSbTable() {
+	var selected string
+	if m.stationboard != nil {
+		if c := m.sbTable.Cursor(); c >= 0 && c < len(m.sbTable.Rows()) && c < len(m.stationboard.Stationboard) {
+			selected = departureKey(m.stationboard.Stationboard[c])
+		}
+	}
+	cursor := m.sbTable.Cursor()
+	m.sbTable = m.buildSbTable()
+	if m.stationboard == nil {
+		return
+	}
+	for i, e := range m.stationboard.Stationboard {
+		if departureKey(e) == selected {
+			cursor = i
+			break
+		}
+	}
+	m.sbTable.SetCursor(min(cursor, max(len(m.stationboard.Stationboard)-1, 0)))
+}
+
+// dropDeparted removes the trains that left (or arrived) more than a minute
+// ago according to their expected time.
+func (m *Model) dropDeparted() {
+	if m.stationboard == nil {
+		return
+	}
+	now := m.now()
+	entries := m.stationboard.Stationboard[:0:0]
+	for _, e := range m.stationboard.Stationboard {
+		if t, err := expectedBoardTime(m.stationboard, e); err == nil && now.Sub(t) >= time.Minute {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	m.stationboard.Stationboard = entries
+}
+
+// expectedBoardTime returns when a train is expected to depart, or arrive on
+// arrival boards.
+func expectedBoardTime(sb *api.StationboardResponse, e api.StationboardEntry) (time.Time, error) {
+	scheduled, expected := boardTime(sb, e)
+	if expected != "" {
+		return parseAPITime(expected)
+	}
+	return parseAPITime(scheduled)
+}
+
+// changedDepartures returns the keys of the departures in next whose delay
+// or platform differ from their previous state in prev.
+func changedDepartures(prev, next []api.StationboardEntry) []string {
+	old := make(map[string]api.StationboardEntry, len(prev))
+	for _, e := range prev {
+		old[departureKey(e)] = e
+	}
+	var keys []string
+	for _, e := range next {
+		k := departureKey(e)
+		o, ok := old[k]
+		if !ok {
+			continue
+		}
+		oldPlatform, _ := stopPlatform(o.Stop)
+		newPlatform, _ := stopPlatform(e.Stop)
+		if o.Stop.Delay != e.Stop.Delay || oldPlatform != newPlatform || o.Stop.Cancelled != e.Stop.Cancelled {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// countdown describes how long until t, e.g. "in 3 min".
+func countdown(t, now time.Time) string {
+	d := t.Sub(now)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("in %d min", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("in %dh%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// liveProvider serves a board that the test changes between refreshes.
+type liveProvider struct {
+	*api.FixtureProvider
+	board []api.StationboardEntry
+}
+
+func (p *liveProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts api.StationboardOptions) (*api.StationboardResponse, error) {
+	return &api.StationboardResponse{
+		Station:      api.Location{Name: station},
+		Stationboard: append([]api.StationboardEntry{}, p.board...),
+	}, nil
+}
+
+func liveEntry(number, departure string, delay int, platform string) api.StationboardEntry {
+	e := api.StationboardEntry{Category: "S", Number: number, To: "Thun"}
+	e.Stop.Departure = "2024-01-01T" + departure + ":00+0100"
+	e.Stop.Platform = "1"
+	e.Stop.Delay = delay
+	if delay > 0 {
+		t, _ := parseAPITime(e.Stop.Departure)
+		e.Stop.Prognosis.Departure = t.Add(time.Duration(delay) * time.Minute).Format("2006-01-02T15:04:05-0700")
+	}
+	e.Stop.Prognosis.Platform = platform
+	return e
+}
+
+func TestLiveStationboard(t *testing.T) {
+	st, _ := store.Open("")
+	p := &liveProvider{
+		FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata")),
+		board: []api.StationboardEntry{
+			liveEntry("1", "12:00", 0, ""),
+			liveEntry("2", "12:05", 0, ""),
+			liveEntry("3", "12:10", 0, ""),
+		},
+	}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	now := time.Date(2024, 1, 1, 12, 1, 30, 0, time.FixedZone("CET", 3600))
+	m.now = func() time.Time { return now }
+	cmd := m.openFavorite(store.Favorite{Name: "Bern", Stations: []string{"Bern"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+
+	p.board[1] = liveEntry("2", "12:05", 2, "4")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if !m.live.on || cmd == nil {
+		t.Fatalf("expected live mode to start")
+	}
+	m.Update(cmd().(tea.BatchMsg)[0]())
+
+	rows := m.sbTable.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("departed train should be removed, got %d rows", len(rows))
+	}
+	if rows[0][1] != "● in 5 min" || rows[1][1] != "in 8 min" {
+		t.Errorf("unexpected countdowns %q and %q", rows[0][1], rows[1][1])
+	}
+
+	// the next tick counts down and, after the interval, refreshes
+	now = now.Add(7 * time.Minute)
+	_, cmd = m.Update(liveTickMsg{seq: m.live.seq})
+	if cmd == nil {
+		t.Fatalf("expected the tick loop to continue")
+	}
+	rows = m.sbTable.Rows()
+	if len(rows) != 1 || rows[0][1] != "in 1 min" {
+		t.Errorf("expected only S3 in 1 min, got %v", rows)
+	}
+
+	seq := m.live.seq
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if m.live.on {
+		t.Fatalf("expected live mode to stop")
+	}
+	if _, cmd := m.Update(liveTickMsg{seq: seq}); cmd != nil {
+		t.Errorf("ticks of a stopped live mode must end")
+	}
+}
+
+func TestLiveStationboardBypassesCache(t *testing.T) {
+	st, _ := store.Open("")
+	p := &liveProvider{
+		FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata")),
+		board:           []api.StationboardEntry{liveEntry("1", "12:10", 0, "")},
+	}
+	cache := api.NewCachedProvider(p, t.TempDir(), time.Hour, time.Minute, time.Hour)
+	m := NewModel(api.NewClientWithProvider(cache), st)
+	now := time.Date(2024, 1, 1, 12, 1, 0, 0, time.FixedZone("CET", 3600))
+	m.now = func() time.Time { return now }
+	cmd := m.openFavorite(store.Favorite{Name: "Bern", Stations: []string{"Bern"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+
+	// well within the cache TTL the delay is already known
+	p.board[0] = liveEntry("1", "12:10", 3, "")
+	now = now.Add(m.liveInterval)
+	_, cmd = m.Update(liveTickMsg{seq: m.live.seq})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if got := m.stationboard.Stationboard[0].Stop.Delay; got != 3 {
+		t.Errorf("expected the live refresh to bypass the cache, delay %d", got)
+	}
+}
+
+func TestLiveStopsWhenLeavingBoard(t *testing.T) {
+	st, _ := store.Open("")
+	e := liveEntry("1", "12:10", 0, "")
+	e.PassList = []api.Stop{{Station: api.Location{Name: "Bern"}}, {Station: api.Location{Name: "Thun"}, Arrival: "2024-01-01T12:30:00+0100"}}
+	p := &liveProvider{FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata")), board: []api.StationboardEntry{e}}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	now := time.Date(2024, 1, 1, 12, 1, 0, 0, time.FixedZone("CET", 3600))
+	m.now = func() time.Time { return now }
+	startLive := func() {
+		t.Helper()
+		cmd := m.openFavorite(store.Favorite{Name: "Bern", Stations: []string{"Bern"}})
+		m.Update(cmd().(tea.BatchMsg)[0]())
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+		m.Update(cmd().(tea.BatchMsg)[0]())
+		if !m.live.on || m.state != stateShowStationboard {
+			t.Fatalf("expected a live board, state %v", m.state)
+		}
+	}
+
+	// connections to a stop of a train on the live board
+	startLive()
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if m.state != stateLoadingConnections || m.live.on {
+		t.Errorf("expected the search to end live mode, state %v", m.state)
+	}
+
+	// cancelling a reload of the live board
+	startLive()
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != stateMenu || m.live.on {
+		t.Errorf("expected cancelling to end live mode, state %v", m.state)
+	}
+
+	// a board opened later is not live
+	startLive()
+	seq := m.live.seq
+	cmd := m.openFavorite(store.Favorite{Name: "Thun", Stations: []string{"Thun"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if m.live.on {
+		t.Errorf("a new board should not start live")
+	}
+	if _, cmd := m.Update(liveTickMsg{seq: seq}); cmd != nil {
+		t.Errorf("ticks of the previous board must end")
+	}
+}
+
+func TestChangedDepartures(t *testing.T) {
+	prev := []api.StationboardEntry{liveEntry("1", "12:00", 0, ""), liveEntry("2", "12:05", 0, ""), liveEntry("3", "12:10", 0, "")}
+	next := []api.StationboardEntry{liveEntry("1", "12:00", 3, ""), liveEntry("2", "12:05", 0, "2"), liveEntry("3", "12:10", 0, "1")}
+	got := changedDepartures(prev, next)
+	if len(got) != 2 || got[0] != departureKey(next[0]) || got[1] != departureKey(next[1]) {
+		t.Errorf("expected delay and platform changes, got %v", got)
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for d, want := range map[time.Duration]string{
+		30 * time.Second: "now",
+		-time.Minute:     "now",
+		3 * time.Minute:  "in 3 min",
+		75 * time.Minute: "in 1h15",
+	} {
+		if got := countdown(now.Add(d), now); got != want {
+			t.Errorf("countdown(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
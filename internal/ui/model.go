@@ -1,11 +1,13 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 	"time"
 
 	api "SBBuddy/internal/api"
+	"SBBuddy/internal/config"
+	"SBBuddy/internal/store"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
@@ -33,8 +35,43 @@ const (
 	stateLoadingConnections
 	stateShowConnectionDetails
 	stateShowConnectionQR
+	stateFavoriteName
+	stateRecentSearches
+	stateShowTrip
+	stateTransportFilter
+	stateSearchOptions
 )
 
+// main menu entries, in the order of Model.choices
+const (
+	menuTimetable = iota
+	menuConnection
+	menuRandom
+	menuRecentSearches
+)
+
+// recallState tracks cycling through recent stations in a station input.
+type recallState struct {
+	input   *textinput.Model
+	matches []string
+	index   int
+}
+
+// resolveState tracks a station lookup started by Enter in a station input.
+// cancel is nil when no lookup is running.
+type resolveState struct {
+	state  appState
+	query  string
+	cancel context.CancelFunc
+}
+
+// fetchState identifies the stationboard or connections request in flight.
+// Results carrying another ID are stale and dropped.
+type fetchState struct {
+	id     int
+	cancel context.CancelFunc
+}
+
 // Model holds the TUI state
 type Model struct {
 	state       appState
@@ -63,6 +100,12 @@ type Model struct {
 	fromStation     *api.Location
 	toStation       *api.Location
 
+	// boardStations are merged into the stationboard; addingStation is set
+	// while another one is being chosen.
+	boardStations []string
+	addingStation bool
+	walk          walkState
+
 	stationboard *api.StationboardResponse
 	connections  *api.ConnectionsResponse
 	err          error
@@ -71,17 +114,51 @@ type Model struct {
 	spinner   spinner.Model
 	isLoading bool
 
-	api *api.Client
+	api   *api.Client
+	store *store.Store
+
+	favoriteInput  textinput.Model
+	favoriteDraft  store.Favorite
+	favoriteReturn appState
+
+	recall       recallState
+	autocomplete autocompleteState
+	resolving    resolveState
+	fetch        fetchState
 
 	selectedConnection    *api.Connection // Track which connection is selected
 	selectedConnectionIdx int
-	detailCursor          int // For navigating within connection details
+	detailCursor          int          // For navigating within connection details
+	expandedStops         map[int]bool // sections showing their intermediate stops
 	qrCode                string
-
-	connTable table.Model
+	status                string // feedback line, e.g. after an export
+	travelClass           int    // 1 or 2, for occupancy forecasts
+	stationboardLimit     int    // departures per stationboard page
+	connectionLimit       int    // connections per search page
+	liveInterval          time.Duration
+
+	connTable  table.Model
+	connPaging connPagingState
+	sbTable    table.Model
+	paging     pagingState
+	live       liveState
+
+	arrivals   bool     // stationboards list arrivals
+	transport  []string // transport types searched, nil for all
+	filter     filterState
+	searchOpts api.ConnectionOptions // restrictions of connection searches
+	options    searchOptionsState
+	trip       *api.StationboardEntry // departure shown in stateShowTrip
+	tripCursor int                    // selected stop of the trip
 
 	returnToDetails bool // refresh after fetching connections
 
+	now func() time.Time // clock of the live stationboard
+
+	// Terminal size, 0 until the first tea.WindowSizeMsg
+	width  int
+	height int
+
 	help help.Model
 	keys KeyMap
 }
@@ -94,8 +171,30 @@ func (m *Model) activeKeys() KeyMap {
 	// Back key isn't available in the main menu
 	k.Back.SetEnabled(m.state != stateMenu)
 
-	// QR code generation only in connection details
+	// q is typed into the favorite nickname
+	if m.state == stateFavoriteName {
+		k.Quit.SetKeys("ctrl+c")
+		k.Quit.SetHelp("ctrl+c", "quit")
+	}
+
+	// QR code generation and calendar export only in connection details
 	k.QR.SetEnabled(m.state == stateShowConnectionDetails)
+	k.Export.SetEnabled(m.state == stateShowConnectionDetails)
+	k.Stops.SetEnabled(m.state == stateShowConnectionDetails)
+	k.Connect.SetEnabled(m.state == stateShowTrip)
+	k.Arrivals.SetEnabled(m.state == stateShowStationboard)
+	k.Live.SetEnabled(m.state == stateShowStationboard)
+	k.Filter.SetEnabled(m.state == stateMenu || m.state == stateShowStationboard || m.state == stateShowConnections)
+	k.Toggle.SetEnabled(m.state == stateTransportFilter || m.state == stateSearchOptions)
+	if m.arrivals {
+		k.Arrivals.SetHelp("a", "show departures")
+	}
+	if m.live.on {
+		k.Live.SetHelp("w", "stop live mode")
+	}
+	if m.state == stateShowConnectionDetails || m.state == stateShowTrip {
+		k.Enter.SetEnabled(false)
+	}
 
 	// Refresh in views that display results
 	switch m.state {
@@ -118,13 +217,40 @@ func (m *Model) activeKeys() KeyMap {
 
 	inputState := m.state == stateConnectionInputFrom || m.state == stateConnectionInputVia || m.state == stateConnectionReady
 	k.AddVia.SetEnabled(inputState)
+	stationInput := m.state == stateStationInput || m.state == stateConnectionInputFrom ||
+		m.state == stateConnectionInputTo || m.state == stateConnectionInputVia
+	k.RecallOlder.SetEnabled(stationInput)
+	k.RecallNewer.SetEnabled(stationInput)
+	hasSuggestions := m.showsAutocomplete(m.activeStationInput())
+	k.NextSuggestion.SetEnabled(hasSuggestions)
+	k.PrevSuggestion.SetEnabled(hasSuggestions)
 	k.DelVia.SetEnabled(inputState && len(m.viaInputs) > 0)
+	if m.state == stateShowStationboard {
+		// stations are added to or removed from a combined board
+		k.AddVia.SetHelp("+", "add station")
+		k.AddVia.SetEnabled(len(m.boardStations) > 0 && m.err == nil)
+		k.DelVia.SetHelp("-", "remove station")
+		k.DelVia.SetEnabled(len(m.boardStations) > 1)
+	}
 
 	k.Modify.SetEnabled(m.state == stateShowConnections)
 
+	k.Favorite.SetEnabled(m.state == stateShowStationboard || m.state == stateShowConnections)
+	onFavorite := m.cursor >= len(m.choices) && m.cursor < len(m.menuItems())
+	k.DelFavorite.SetEnabled(m.state == stateMenu && onFavorite)
+
 	return k
 }
 
+// menuItems returns the fixed main menu choices followed by the favorites.
+func (m *Model) menuItems() []string {
+	items := append([]string{}, m.choices...)
+	for _, fav := range m.store.Favorites.Items {
+		items = append(items, "★ "+fav.Label())
+	}
+	return items
+}
+
 // prepareDateTime initializes the date/time selection with defaults.
 func (m *Model) prepareDateTime(allowArrival, reuse bool) {
 	if reuse && !m.lastSearchTime.IsZero() {
@@ -224,8 +350,16 @@ func (m *Model) resetConnectionInputs() {
 	m.toStation = nil
 }
 
-// InitialModel initializes the Bubble Tea model with optimizations
+// InitialModel initializes the Bubble Tea model backed by the opendata.ch API
+// using the default HTTP settings and an in-memory store.
 func InitialModel() *Model {
+	st, _ := store.Open("")
+	return NewModel(api.NewClient(config.Default().HTTPClient()), st)
+}
+
+// NewModel initializes the Bubble Tea model using the given API client, which
+// allows the TUI to run against any api.Provider, and the user data store.
+func NewModel(client *api.Client, st *store.Store) *Model {
 	input := textinput.New()
 	input.Placeholder = "Station name"
 	input.Focus()
@@ -242,16 +376,22 @@ func InitialModel() *Model {
 	to.CharLimit = 50
 	to.Width = 40
 
+	favInput := textinput.New()
+	favInput.Placeholder = "Nickname, e.g. Home → Office"
+	favInput.CharLimit = 50
+	favInput.Width = 40
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	cols := []table.Column{
-		{Title: "Departure", Width: 9},
-		{Title: "Arrival", Width: 9},
-		{Title: "Delay", Width: 6},
+		{Title: "Departure", Width: 13},
+		{Title: "Arrival", Width: 13},
+		{Title: "Delay", Width: 9},
 		{Title: "Duration", Width: 8},
 		{Title: "Changes", Width: 8},
+		{Title: "Load", Width: 4},
 		{Title: "From → To", Width: 25},
 	}
 	connTbl := table.New(
@@ -261,34 +401,39 @@ func InitialModel() *Model {
 	)
 	connTbl.SetStyles(table.DefaultStyles())
 
-	// Create HTTP client with connection pooling and optimized timeouts
-	client := &http.Client{
-		Timeout: 8 * time.Second, // Slightly reduced timeout
-		Transport: &http.Transport{
-			MaxIdleConns:        10,               // Allow connection reuse
-			MaxIdleConnsPerHost: 5,                // Per host connection reuse
-			IdleConnTimeout:     30 * time.Second, // Keep connections alive
-			DisableCompression:  false,            // Enable compression
-		},
+	return &Model{
+		state:             stateMenu,
+		returnState:       stateMenu,
+		choices:           []string{"Lookup timetable", "Find connection", "Random connection", "Recent searches"},
+		cursor:            0,
+		stationInput:      input,
+		fromInput:         from,
+		toInput:           to,
+		viaInputs:         nil,
+		viaStations:       nil,
+		viaIndex:          0,
+		dateTime:          time.Now().Truncate(time.Minute),
+		allowArrival:      false,
+		spinner:           s,
+		connTable:         connTbl,
+		api:               client,
+		store:             st,
+		favoriteInput:     favInput,
+		travelClass:       2,
+		transport:         normalizeTransport(st.Preferences.Transportations),
+		stationboardLimit: api.DefaultStationboardLimit,
+		connectionLimit:   api.DefaultConnectionLimit,
+		liveInterval:      DefaultLiveInterval,
+		now:               time.Now,
+		help:              help.New(),
+		keys:              DefaultKeyMap(),
 	}
+}
 
-	return &Model{
-		state:        stateMenu,
-		returnState:  stateMenu,
-		choices:      []string{"Lookup timetable", "Find connection", "Random connection"},
-		cursor:       0,
-		stationInput: input,
-		fromInput:    from,
-		toInput:      to,
-		viaInputs:    nil,
-		viaStations:  nil,
-		viaIndex:     0,
-		dateTime:     time.Now().Truncate(time.Minute),
-		allowArrival: false,
-		spinner:      s,
-		connTable:    connTbl,
-		api:          api.NewClient(client),
-		help:         help.New(),
-		keys:         DefaultKeyMap(),
+// SetTravelClass selects the class (1 or 2) whose occupancy forecast is
+// shown. Other values are ignored.
+func (m *Model) SetTravelClass(class int) {
+	if class == 1 || class == 2 {
+		m.travelClass = class
 	}
 }
@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// monitorTimeout bounds each board fetch of the monitor.
+const monitorTimeout = 5 * time.Second
+
+// Monitor is the full-screen departure monitor of `SBBuddy monitor`: a large
+// clock above the live stationboards of one or more stations side by side.
+// It refreshes on its own and ignores every key but ctrl+c.
+type Monitor struct {
+	api      *api.Client
+	stations []string
+	opts     api.StationboardOptions
+	interval time.Duration
+
+	boards  []*api.StationboardResponse
+	errs    []error
+	updated time.Time
+	pending int // boards of the last refresh not received yet
+
+	width  int
+	height int
+	now    func() time.Time
+}
+
+// monitorTickMsg advances the clock of the monitor every second.
+type monitorTickMsg struct{}
+
+// monitorBoardMsg carries the board of the station at index.
+type monitorBoardMsg struct {
+	index int
+	sb    *api.StationboardResponse
+	err   error
+}
+
+var (
+	clockStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Bold(true)
+	monitorPanelStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+	monitorHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("25")).Padding(0, 1)
+	dueSoonStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
+)
+
+// NewMonitor creates a monitor for the given stations.
+func NewMonitor(client *api.Client, stations []string, opts api.StationboardOptions) *Monitor {
+	return &Monitor{
+		api:      client,
+		stations: stations,
+		opts:     opts,
+		interval: DefaultLiveInterval,
+		boards:   make([]*api.StationboardResponse, len(stations)),
+		errs:     make([]error, len(stations)),
+		now:      time.Now,
+	}
+}
+
+// SetInterval sets how often the boards are fetched again. Values below ten
+// seconds are ignored.
+func (m *Monitor) SetInterval(d time.Duration) {
+	if d >= 10*time.Second {
+		m.interval = d
+	}
+}
+
+func (m *Monitor) Init() tea.Cmd {
+	return tea.Batch(m.refresh(), m.tick())
+}
+
+func (m *Monitor) tick() tea.Cmd {
+	return tea.Tick(liveTick, func(time.Time) tea.Msg { return monitorTickMsg{} })
+}
+
+// refresh fetches the boards of all stations concurrently. It does nothing
+// while the boards of the previous refresh are still being fetched.
+func (m *Monitor) refresh() tea.Cmd {
+	if m.pending > 0 {
+		return nil
+	}
+	m.updated = m.now()
+	m.pending = len(m.stations)
+	cmds := make([]tea.Cmd, len(m.stations))
+	for i, station := range m.stations {
+		i, station, client, opts := i, station, m.api, m.opts
+		cmds[i] = func() tea.Msg {
+			// like the live board, refreshes skip the cache
+			ctx, cancel := context.WithTimeout(api.RefreshCache(context.Background()), monitorTimeout)
+			defer cancel()
+			sb, err := client.FetchStationboardWith(ctx, station, "", "", opts)
+			return monitorBoardMsg{index: i, sb: sb, err: err}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Monitor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+	case monitorTickMsg:
+		if m.pending == 0 && m.now().Sub(m.updated) >= m.interval {
+			return m, tea.Batch(m.refresh(), m.tick())
+		}
+		return m, m.tick()
+	case monitorBoardMsg:
+		if m.pending > 0 {
+			m.pending--
+		}
+		// keep showing the last board when an update fails
+		m.errs[msg.index] = msg.err
+		if msg.err == nil {
+			m.boards[msg.index] = msg.sb
+		}
+	}
+	return m, nil
+}
+
+func (m *Monitor) View() string {
+	now := m.now()
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	clock := lipgloss.PlaceHorizontal(width, lipgloss.Center, clockStyle.Render(bigClock(now)))
+	date := lipgloss.PlaceHorizontal(width, lipgloss.Center, now.Format("Monday 02.01.2006"))
+	header := clock + "\n" + date
+
+	// rows left for the boards below the clock, the panel border and header
+	rows := 10
+	if m.height > 0 {
+		rows = max(m.height-lipgloss.Height(header)-5, 1)
+	}
+	panelWidth := max(width/max(len(m.stations), 1)-4, 30)
+
+	panels := make([]string, len(m.stations))
+	for i, station := range m.stations {
+		panels[i] = monitorPanelStyle.Width(panelWidth).Render(m.renderBoard(i, station, panelWidth-2, rows, now))
+	}
+	return header + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, panels...)
+}
+
+// renderBoard renders the departures of one station within width cells,
+// hiding trains that have left.
+func (m *Monitor) renderBoard(i int, station string, width, rows int, now time.Time) string {
+	sb := m.boards[i]
+	if sb != nil && sb.Station.Name != "" {
+		station = sb.Station.Name
+	}
+	title := station
+	if sb != nil && sb.Arrivals {
+		title = "Arrivals " + station
+	}
+	var s strings.Builder
+	s.WriteString(monitorHeaderStyle.Width(width).Render(truncate(title, width-2)) + "\n")
+	switch {
+	case m.errs[i] != nil:
+		s.WriteString(changedStyle.Render(truncate("⚠ "+m.errs[i].Error(), width)) + "\n")
+	case sb != nil && !sb.CachedAt.IsZero():
+		s.WriteString(changedStyle.Render("⚠ offline, cached "+sb.CachedAt.Local().Format("15:04")) + "\n")
+	}
+	if sb == nil {
+		if m.errs[i] == nil {
+			s.WriteString("Loading...")
+		}
+		return s.String()
+	}
+
+	shown := 0
+	for _, e := range sb.Stationboard {
+		t, err := expectedBoardTime(sb, e)
+		if err == nil && now.Sub(t) >= time.Minute {
+			continue
+		}
+		if shown == rows {
+			break
+		}
+		s.WriteString(monitorRow(sb, e, width, now) + "\n")
+		shown++
+	}
+	if shown == 0 {
+		s.WriteString("No departures")
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+// monitorRow renders a departure as "12:34 +2  in 3 min  IR15  Luzern  7".
+func monitorRow(sb *api.StationboardResponse, e api.StationboardEntry, width int, now time.Time) string {
+	scheduled, _ := boardTime(sb, e)
+	due := "-"
+	if t, err := expectedBoardTime(sb, e); err == nil {
+		due = countdown(t, now)
+	}
+	delay := formatDelay(e.Stop.Delay)
+	if delay == "." {
+		delay = ""
+	}
+	platform, changed := stopPlatform(e.Stop)
+
+	left := fmt.Sprintf("%-5s %-3s %-9s %-6s ", formatISOTime(scheduled), delay, due, truncate(e.Category+e.Number, 6))
+	right := " " + platform
+	dir := truncate(boardDirection(sb, e, width), max(width-lipgloss.Width(left)-lipgloss.Width(right), 5))
+	pad := max(width-lipgloss.Width(left)-lipgloss.Width(dir)-lipgloss.Width(right), 0)
+
+	switch {
+	case e.Stop.Cancelled:
+		return cancelledStyle.Render(left+dir) + strings.Repeat(" ", pad) + " cancelled"
+	case changed:
+		right = " " + changedStyle.Render(platform)
+	}
+	if due == "now" || strings.HasPrefix(due, "in 1 ") || strings.HasPrefix(due, "in 2 ") {
+		left = dueSoonStyle.Render(left)
+	}
+	return left + dir + strings.Repeat(" ", pad) + right
+}
+
+// bigDigits are the glyphs of the monitor clock, five rows each.
+var bigDigits = map[rune][5]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {" ██", "  █", "  █", "  █", "  █"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", "███", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", "  █", "  █", "  █"},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	':': {" ", "█", " ", "█", " "},
+}
+
+// bigClock renders t as HH:MM:SS in large block digits.
+func bigClock(t time.Time) string {
+	var lines [5]string
+	for i, r := range t.Format("15:04:05") {
+		glyph := bigDigits[r]
+		for row := range lines {
+			if i > 0 {
+				lines[row] += " "
+			}
+			lines[row] += glyph[row]
+		}
+	}
+	return strings.Join(lines[:], "\n")
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newFixtureMonitor(t *testing.T, stations ...string) *Monitor {
+	t.Helper()
+	client := api.NewClientWithProvider(api.NewFixtureProvider(filepath.Join("..", "..", "testdata")))
+	m := NewMonitor(client, stations, api.StationboardOptions{})
+	now := time.Date(2024, 1, 1, 12, 25, 0, 0, time.FixedZone("CET", 3600))
+	m.now = func() time.Time { return now }
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return m
+}
+
+// refreshMonitor fetches the boards of m and applies them.
+func refreshMonitor(m *Monitor) {
+	msg := m.refresh()()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			m.Update(cmd())
+		}
+		return
+	}
+	m.Update(msg)
+}
+
+func TestMonitorBoards(t *testing.T) {
+	m := newFixtureMonitor(t, "Chur", "Landquart")
+	refreshMonitor(m)
+	out := m.View()
+	for _, want := range []string{"RE1234 Chur → Basel SBB", "in 5 min", "Monday 01.01.2024", "█"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in monitor view:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "RE1234"); n != 2 {
+		t.Errorf("expected two boards side by side, got %d:\n%s", n, out)
+	}
+
+	// a failing update keeps the last board
+	m.Update(monitorBoardMsg{index: 1, err: errors.New("offline")})
+	if out := m.View(); !strings.Contains(out, "offline") || m.boards[1] == nil {
+		t.Errorf("expected the last board with a warning:\n%s", out)
+	}
+}
+
+func TestMonitorSkipsRefreshInProgress(t *testing.T) {
+	m := newFixtureMonitor(t, "Chur", "Landquart")
+	cmd := m.refresh()
+	if cmd == nil {
+		t.Fatal("expected the boards to be fetched")
+	}
+	start := m.now()
+	m.now = func() time.Time { return start.Add(m.interval) }
+	if _, cmd := m.Update(monitorTickMsg{}); cmd == nil || !m.updated.Equal(start) || m.refresh() != nil {
+		t.Fatal("expected only the next tick while the boards are fetched")
+	}
+
+	for _, c := range cmd().(tea.BatchMsg) {
+		m.Update(c())
+	}
+	if m.pending != 0 || m.refresh() == nil {
+		t.Errorf("expected a refresh once all boards arrived, %d pending", m.pending)
+	}
+}
+
+func TestMonitorBypassesCache(t *testing.T) {
+	p := &liveProvider{
+		FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata")),
+		board:           []api.StationboardEntry{liveEntry("1", "12:10", 0, "")},
+	}
+	cache := api.NewCachedProvider(p, t.TempDir(), time.Hour, time.Minute, time.Hour)
+	m := NewMonitor(api.NewClientWithProvider(cache), []string{"Bern"}, api.StationboardOptions{})
+	refreshMonitor(m)
+	p.board[0] = liveEntry("1", "12:10", 3, "")
+	refreshMonitor(m)
+	if got := m.boards[0].Stationboard[0].Stop.Delay; got != 3 {
+		t.Errorf("expected the refresh to bypass the cache, delay %d", got)
+	}
+}
+
+func TestMonitorHidesDeparted(t *testing.T) {
+	m := newFixtureMonitor(t, "Chur")
+	refreshMonitor(m)
+	now := time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	if out := m.View(); !strings.Contains(out, "No departures") {
+		t.Errorf("departed train should be hidden:\n%s", out)
+	}
+}
+
+func TestMonitorKeys(t *testing.T) {
+	m := newFixtureMonitor(t, "Chur")
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("q")}, {Type: tea.KeyEsc}, {Type: tea.KeyEnter}} {
+		if _, cmd := m.Update(k); cmd != nil {
+			t.Errorf("key %v should be ignored", k)
+		}
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Errorf("ctrl+c should quit")
+	}
+}
+
+func TestBigClock(t *testing.T) {
+	lines := strings.Split(bigClock(time.Date(2024, 1, 1, 9, 5, 0, 0, time.UTC)), "\n")
+	if len(lines) != 5 || lines[0] != "███ ███   ███ ███   ███ ███" {
+		t.Errorf("unexpected clock:\n%s", strings.Join(lines, "\n"))
+	}
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	api "SBBuddy/internal/api"
+)
+
+// searchOptionsState holds the connection search options panel while it is
+// open. Changes are made on draft and only applied on enter.
+type searchOptionsState struct {
+	draft  api.ConnectionOptions
+	cursor int
+}
+
+// searchOptionRows is the number of rows of the options panel: the four
+// switches and the accessibility level.
+const searchOptionRows = 5
+
+// SetConnectionOptions sets the restrictions (direct, sleeper, couchette,
+// bike, accessibility) applied to the connection searches of this session.
+func (m *Model) SetConnectionOptions(opts api.ConnectionOptions) {
+	m.searchOpts = api.ConnectionOptions{
+		Direct:        opts.Direct,
+		Sleeper:       opts.Sleeper,
+		Couchette:     opts.Couchette,
+		Bike:          opts.Bike,
+		Accessibility: opts.Accessibility,
+	}
+}
+
+// connectionOptions returns the options of a connection search, arrival
+// selecting an arrival time search.
+func (m *Model) connectionOptions(arrival bool) api.ConnectionOptions {
+	opts := m.searchOpts
+	opts.Arrival = arrival
+	opts.Transportations = m.transport
+	opts.Limit = m.connectionLimit
+	return opts
+}
+
+// accessibilityLabels names the api.AccessibilityLevels for display.
+var accessibilityLabels = map[string]string{
+	"":                     "no requirement",
+	"independent_boarding": "independent boarding",
+	"assisted_boarding":    "assisted boarding",
+	"advanced_notice":      "assistance booked in advance",
+}
+
+// searchOptionsLabel describes the restrictions of opts, e.g.
+// "direct, bike", or "none".
+func searchOptionsLabel(opts api.ConnectionOptions) string {
+	var parts []string
+	if opts.Direct {
+		parts = append(parts, "direct")
+	}
+	if opts.Sleeper {
+		parts = append(parts, "sleeper")
+	}
+	if opts.Couchette {
+		parts = append(parts, "couchette")
+	}
+	if opts.Bike {
+		parts = append(parts, "bike")
+	}
+	if opts.Accessibility != "" {
+		parts = append(parts, accessibilityLabels[opts.Accessibility])
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// optionsNote is the line shown under connection titles while search
+// options restrict the results.
+func (m *Model) optionsNote() string {
+	label := searchOptionsLabel(m.searchOpts)
+	if label == "none" {
+		return ""
+	}
+	return "Options: " + label + "\n"
+}
+
+// openSearchOptions shows the search options panel.
+func (m *Model) openSearchOptions() {
+	m.options = searchOptionsState{draft: m.searchOpts}
+	m.state = stateSearchOptions
+}
+
+// toggleSearchOption flips the switch under the cursor. On the
+// accessibility row it cycles through the levels.
+func (m *Model) toggleSearchOption() {
+	d := &m.options.draft
+	switch m.options.cursor {
+	case 0:
+		d.Direct = !d.Direct
+	case 1:
+		d.Sleeper = !d.Sleeper
+	case 2:
+		d.Couchette = !d.Couchette
+	case 3:
+		d.Bike = !d.Bike
+	case 4:
+		levels := append([]string{""}, api.AccessibilityLevels...)
+		for i, l := range levels {
+			if l == d.Accessibility {
+				d.Accessibility = levels[(i+1)%len(levels)]
+				break
+			}
+		}
+	}
+}
+
+// applySearchOptions keeps the edited options and returns to the search
+// form.
+func (m *Model) applySearchOptions() {
+	m.searchOpts = m.options.draft
+	m.state = stateConnectionReady
+}
+
+// searchOptionsView renders the search options panel.
+func (m *Model) searchOptionsView() string {
+	d := m.options.draft
+	rows := []struct {
+		label string
+		on    bool
+	}{
+		{"Direct connections only", d.Direct},
+		{"Sleeper", d.Sleeper},
+		{"Couchette", d.Couchette},
+		{"Bike transport", d.Bike},
+	}
+
+	var s strings.Builder
+	s.WriteString("⚙️ Search options:\n\n")
+	cursor := func(i int) string {
+		if i == m.options.cursor {
+			return ">"
+		}
+		return " "
+	}
+	for i, r := range rows {
+		box := "[ ]"
+		if r.on {
+			box = "[x]"
+		}
+		s.WriteString(fmt.Sprintf("%s %s %s\n", cursor(i), box, r.label))
+	}
+	s.WriteString(fmt.Sprintf("%s Accessibility: %s\n", cursor(len(rows)), accessibilityLabels[d.Accessibility]))
+	return s.String()
+}
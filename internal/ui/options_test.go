@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// optionsProvider serves fixtures and records the options of connection
+// searches.
+type optionsProvider struct {
+	*api.FixtureProvider
+	opts []api.ConnectionOptions
+}
+
+func (p *optionsProvider) Connections(ctx context.Context, from, to string, via []string, date, timeStr string, opts api.ConnectionOptions) (*api.ConnectionsResponse, error) {
+	p.opts = append(p.opts, opts)
+	return p.FixtureProvider.Connections(ctx, from, to, via, date, timeStr, opts)
+}
+
+func TestSearchOptions(t *testing.T) {
+	st, _ := store.Open("")
+	p := &optionsProvider{FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata"))}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	m.state = stateConnectionReady
+	m.fromStation = &api.Location{Name: "Chur"}
+	m.toStation = &api.Location{Name: "Bern"}
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	for i := 0; i < 3; i++ {
+		m.Update(down)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != stateSearchOptions {
+		t.Fatalf("expected options panel, got state %v", m.state)
+	}
+	m.Update(space)
+	for i := 0; i < 4; i++ {
+		m.Update(down)
+	}
+	m.Update(space)
+	m.Update(space)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != stateConnectionReady {
+		t.Fatalf("expected search form, got state %v", m.state)
+	}
+	if got := searchOptionsLabel(m.searchOpts); got != "direct, assisted boarding" {
+		t.Errorf("unexpected options %q", got)
+	}
+
+	cmd := m.openFavorite(store.Favorite{Name: "office", Stations: []string{"Chur", "Bern"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if len(p.opts) != 1 || !p.opts[0].Direct || p.opts[0].Accessibility != "assisted_boarding" {
+		t.Errorf("expected options in the request, got %+v", p.opts)
+	}
+}
+
+func TestSearchOptionsCancel(t *testing.T) {
+	m := InitialModel()
+	m.state = stateConnectionReady
+	m.openSearchOptions()
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != stateConnectionReady || m.searchOpts.Direct {
+		t.Errorf("expected unchanged options back in the form, got state %v and %+v", m.state, m.searchOpts)
+	}
+}
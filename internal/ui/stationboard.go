@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stationboardPageMsg carries the later departures requested by scrolling
+// past the end of the stationboard.
+type stationboardPageMsg struct {
+	id  int
+	sb  *api.StationboardResponse
+	err error
+}
+
+// pagingState tracks loading of further stationboard pages.
+type pagingState struct {
+	loading   bool
+	exhausted bool // the last page added no departures
+}
+
+// SetStationboardLimit sets how many departures are fetched per stationboard
+// page. Values below 1 are ignored.
+func (m *Model) SetStationboardLimit(limit int) {
+	if limit > 0 {
+		m.stationboardLimit = limit
+	}
+}
+
+// stationboardOptions returns the options of stationboard requests.
+func (m *Model) stationboardOptions() api.StationboardOptions {
+	return api.StationboardOptions{Limit: m.stationboardLimit, Arrivals: m.arrivals, Transportations: m.transport}
+}
+
+// reloadStationboard fetches the board of the selected stations again for
+// the last searched time, or from now on if none was chosen or the walk to
+// the station applies.
+func (m *Model) reloadStationboard() tea.Cmd {
+	m.isLoading = true
+	m.state = stateLoadingConnections
+	if !m.lastSearchTime.IsZero() && !m.walk.on {
+		date := m.lastSearchTime.Format("2006-01-02")
+		tm := m.lastSearchTime.Format("15:04")
+		ctx, id := m.startFetch()
+		return tea.Batch(m.api.FetchStationboardsCmd(ctx, id, m.boardStations, date, tm, m.stationboardOptions()), m.spinner.Tick)
+	}
+	return m.fetchBoardFromNow()
+}
+
+// fetchBoardFromNow fetches the board of the selected stations from now on,
+// or from when the walk to the station is over.
+func (m *Model) fetchBoardFromNow() tea.Cmd {
+	m.lastSearchTime = m.now().Truncate(time.Minute)
+	m.lastSearchArrival = false
+	date, tm := m.walkStart()
+	ctx, id := m.startFetch()
+	return tea.Batch(m.api.FetchStationboardsCmd(ctx, id, m.boardStations, date, tm, m.stationboardOptions()), m.spinner.Tick)
+}
+
+// useBoardStation puts the station chosen in the station input on the
+// stationboard: alone on a new board, next to the others when one is added.
+// A new date or time for the shown board keeps its stations.
+func (m *Model) useBoardStation() {
+	if m.selectedStation == nil || (!m.addingStation && len(m.boardStations) > 0) {
+		return
+	}
+	m.addingStation = false
+	for _, s := range m.boardStations {
+		if strings.EqualFold(s, m.selectedStation.Name) {
+			return
+		}
+	}
+	m.boardStations = append(m.boardStations, m.selectedStation.Name)
+}
+
+// addBoardStation asks for another station whose departures are merged into
+// the stationboard.
+func (m *Model) addBoardStation() {
+	m.stopLive()
+	m.addingStation = true
+	m.stationInput.SetValue("")
+	m.stationInput.Focus()
+	m.state = stateStationInput
+}
+
+// removeBoardStation drops the station added last to a combined board.
+func (m *Model) removeBoardStation() tea.Cmd {
+	if len(m.boardStations) < 2 {
+		return nil
+	}
+	m.stopLive()
+	m.boardStations = m.boardStations[:len(m.boardStations)-1]
+	return m.reloadStationboard()
+}
+
+// recordBoard remembers the stations of a fetched board in the history, each
+// on its own since a combined board cannot be searched again as one.
+func (m *Model) recordBoard(sb *api.StationboardResponse) {
+	if !sb.Combined() {
+		if sb.Station.Name != "" {
+			m.recordSearch([]string{sb.Station.Name})
+		}
+		return
+	}
+	for _, s := range sb.Stations {
+		m.recordSearch([]string{s.Name})
+	}
+}
+
+// buildSbTable builds the stationboard table sized for the terminal.
+func (m *Model) buildSbTable() table.Model {
+	var live *liveView
+	if m.live.on {
+		live = &liveView{now: m.now(), flash: m.live.flash}
+	}
+	t := buildStationboardTable(m.stationboard, m.width, live, m.walkView())
+	rows := 0
+	if m.stationboard != nil {
+		rows = len(m.stationboard.Stationboard)
+	}
+	t.SetHeight(m.tableHeight(rows, 4) + 1)
+	return t
+}
+
+// showStationboard displays a freshly fetched stationboard with the cursor
+// on the first departure.
+func (m *Model) showStationboard(sb *api.StationboardResponse) {
+	// a new board is not live, live updates come through handleLiveBoard
+	m.stopLive()
+	m.stationboard = sb
+	m.paging = pagingState{}
+	m.dropUnreachable()
+	m.sbTable = m.buildSbTable()
+}
+
+// loadMoreDepartures fetches the departures following the last one shown.
+func (m *Model) loadMoreDepartures() tea.Cmd {
+	if m.paging.loading || m.paging.exhausted || len(m.boardStations) == 0 {
+		return nil
+	}
+	t, err := lastStationboardTime(m.stationboard)
+	if err != nil {
+		return nil
+	}
+	ctx, id := m.startFetch()
+	m.paging.loading = true
+	client, stations, opts := m.api, m.boardStations, m.stationboardOptions()
+	date, tm := t.Format("2006-01-02"), t.Format("15:04")
+	return func() tea.Msg {
+		sb, err := client.FetchStationboards(ctx, stations, date, tm, opts)
+		return stationboardPageMsg{id: id, sb: sb, err: err}
+	}
+}
+
+// handleStationboardPage appends a page of later departures and moves the
+// cursor onto the first new one.
+func (m *Model) handleStationboardPage(msg stationboardPageMsg) {
+	if !m.finishFetch(msg.id) {
+		return
+	}
+	m.paging.loading = false
+	if msg.err != nil {
+		m.status = fmt.Sprintf("❌ failed to load later departures: %v", msg.err)
+		return
+	}
+	var added int
+	if msg.sb != nil {
+		m.stationboard.Stationboard, added = mergeDepartures(m.stationboard.Stationboard, msg.sb.Stationboard)
+	}
+	if added == 0 {
+		m.paging.exhausted = true
+		m.status = "No later departures"
+		return
+	}
+	cursor := m.sbTable.Cursor()
+	m.sbTable = m.buildSbTable()
+	m.sbTable.SetCursor(cursor + 1)
+}
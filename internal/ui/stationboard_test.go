@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pagedProvider serves a board of hourly departures, limit entries starting
+// at the requested hour.
+type pagedProvider struct {
+	*api.FixtureProvider
+	opts []api.StationboardOptions
+}
+
+func (p *pagedProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts api.StationboardOptions) (*api.StationboardResponse, error) {
+	p.opts = append(p.opts, opts)
+	start := 8
+	if timeStr != "" {
+		fmt.Sscanf(timeStr, "%d:", &start)
+	}
+	sb := &api.StationboardResponse{Station: api.Location{Name: station}}
+	for h := start; h < start+opts.Limit && h < 24; h++ {
+		sb.Stationboard = append(sb.Stationboard, api.StationboardEntry{
+			Category: "IR",
+			Number:   fmt.Sprint(h),
+			To:       "Basel SBB",
+			Stop:     api.Stop{Departure: fmt.Sprintf("2024-01-01T%02d:00:00+01:00", h)},
+		})
+	}
+	return sb, nil
+}
+
+func newPagedModel(t *testing.T, limit int) (*Model, *pagedProvider) {
+	t.Helper()
+	st, _ := store.Open("")
+	p := &pagedProvider{FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata"))}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	m.SetStationboardLimit(limit)
+	cmd := m.openFavorite(store.Favorite{Name: "Chur", Stations: []string{"Chur"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	return m, p
+}
+
+func TestStationboardLazyLoading(t *testing.T) {
+	m, p := newPagedModel(t, 3)
+	if m.state != stateShowStationboard || len(m.stationboard.Stationboard) != 3 {
+		t.Fatalf("unexpected board %+v in state %v", m.stationboard, m.state)
+	}
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for i := 0; i < 2; i++ {
+		if _, cmd := m.Update(down); cmd != nil {
+			t.Fatalf("no page expected before the last row")
+		}
+	}
+	_, cmd := m.Update(down)
+	if cmd == nil {
+		t.Fatalf("expected later departures to be fetched past the end")
+	}
+	m.Update(cmd())
+
+	// The next page starts at the last departure, which must not repeat.
+	if got := len(m.stationboard.Stationboard); got != 5 {
+		t.Fatalf("expected 5 departures after merging, got %d", got)
+	}
+	if m.sbTable.Cursor() != 3 || m.sbTable.SelectedRow()[2] != "IR11" {
+		t.Errorf("cursor should move onto the first new departure, got %d", m.sbTable.Cursor())
+	}
+	for _, o := range p.opts {
+		if o.Limit != 3 {
+			t.Errorf("expected configured limit in every request, got %v", p.opts)
+		}
+	}
+}
+
+func TestStationboardLazyLoadingStale(t *testing.T) {
+	m, _ := newPagedModel(t, 2)
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if cmd == nil {
+		t.Fatalf("expected page fetch")
+	}
+	page := cmd()
+
+	// A refresh supersedes the page request.
+	_, refresh := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m.Update(page)
+	if len(m.stationboard.Stationboard) != 2 {
+		t.Errorf("stale page must be dropped, got %d departures", len(m.stationboard.Stationboard))
+	}
+	m.Update(refresh().(tea.BatchMsg)[0]())
+	if m.paging.loading {
+		t.Errorf("a new board should reset paging")
+	}
+}
+
+func TestMergeDepartures(t *testing.T) {
+	a := api.StationboardEntry{Category: "S", Number: "1", Stop: api.Stop{Departure: "2024-01-01T12:00:00+01:00"}}
+	b := api.StationboardEntry{Category: "S", Number: "2", Stop: api.Stop{Departure: "2024-01-01T12:00:00+01:00"}}
+	merged, added := mergeDepartures([]api.StationboardEntry{a}, []api.StationboardEntry{a, b, b})
+	if added != 1 || len(merged) != 2 || merged[1].Number != "2" {
+		t.Errorf("unexpected merge %v (%d added)", merged, added)
+	}
+}
+
+func TestStationboardToggleArrivals(t *testing.T) {
+	m, p := newPagedModel(t, 2)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if cmd == nil || m.state != stateLoadingConnections {
+		t.Fatalf("expected the board to be fetched again, got %v", m.state)
+	}
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if last := p.opts[len(p.opts)-1]; !last.Arrivals {
+		t.Errorf("expected an arrivals request, got %+v", last)
+	}
+	if !m.stationboard.Arrivals || !strings.Contains(m.View(), "Arrivals at Chur") {
+		t.Errorf("expected arrivals board:\n%s", m.View())
+	}
+	if !m.activeKeys().Arrivals.Enabled() || m.activeKeys().Arrivals.Help().Desc != "show departures" {
+		t.Errorf("toggle should offer to switch back")
+	}
+}
+
+func TestStationboardArrivalsOrigin(t *testing.T) {
+	sb := &api.StationboardResponse{
+		Station:  api.Location{Name: "Bern"},
+		Arrivals: true,
+		Stationboard: []api.StationboardEntry{{
+			Category: "IC",
+			Number:   "8",
+			To:       "Brig",
+			Stop:     api.Stop{Arrival: "2024-01-01T12:56:00+01:00", Departure: "2024-01-01T13:04:00+01:00"},
+			PassList: []api.Stop{{Station: api.Location{Name: "Zürich HB"}}, {Station: api.Location{Name: "Bern"}}},
+		}},
+	}
+	out := renderStationboardTable(sb)
+	for _, want := range []string{"Origin", "from Zürich HB", "12:56"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("arrival board misses %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Brig") || strings.Contains(out, "13:04") {
+		t.Errorf("arrival board should not show the onward trip:\n%s", out)
+	}
+	row := buildStationboardTable(sb, 0, nil, nil).Rows()[0]
+	if row[0] != "12:56" || row[3] != "from Zürich HB" {
+		t.Errorf("unexpected row %v", row)
+	}
+}
+
+func TestCombinedStationboard(t *testing.T) {
+	m, p := newPagedModel(t, 3)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	if m.state != stateStationInput || !m.addingStation {
+		t.Fatalf("expected the station input, got %v", m.state)
+	}
+	m.chooseStation(&api.Location{Name: "Landquart"})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+
+	if m.state != stateShowStationboard || !m.stationboard.Combined() || len(m.stationboard.Stationboard) != 6 {
+		t.Fatalf("expected a combined board, got %+v in state %v", m.stationboard, m.state)
+	}
+	rows := m.sbTable.Rows()
+	if rows[0][1] != "Chur" || rows[1][1] != "Landquart" || rows[0][0] != rows[1][0] {
+		t.Errorf("expected departures of both stations side by side, got %v and %v", rows[0], rows[1])
+	}
+	if !strings.Contains(m.View(), "Stationboard for Chur + Landquart") {
+		t.Errorf("expected both stations in the title:\n%s", m.View())
+	}
+	if len(p.opts) != 3 {
+		t.Errorf("expected one request per station, got %d", len(p.opts))
+	}
+
+	// favorites hold a single station
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if m.state != stateShowStationboard || m.status == "" {
+		t.Errorf("combined boards should not be saved as favorites")
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if m.stationboard.Combined() || len(m.sbTable.Rows()[0]) != 5 {
+		t.Errorf("expected the board of Chur alone, got %v", m.sbTable.Rows()[0])
+	}
+}
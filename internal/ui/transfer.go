@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// minTransfer is the time needed to change trains on the same platform
+	// or after a walk.
+	minTransfer = 2 * time.Minute
+	// minPlatformTransfer is the time needed to change to another platform.
+	minPlatformTransfer = 4 * time.Minute
+)
+
+// transferRisk grades how likely a change of trains works out.
+type transferRisk int
+
+const (
+	riskNone   transferRisk = iota
+	riskTight               // less than the time needed to change
+	riskBroken              // the next train leaves before you arrive
+)
+
+var brokenStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+// transfer is a change between two journey sections of a connection.
+type transfer struct {
+	section   int    // index of the journey section changed to
+	station   string // where the change happens
+	from, to  string // arrival and departure platform, "" if unknown
+	scheduled time.Duration
+	expected  time.Duration // buffer with the current delays
+	risk      transferRisk
+}
+
+// platformChange reports whether the change leads to another platform.
+// Sectors as in "7A" and "7CD" are part of the same platform.
+func (t transfer) platformChange() bool {
+	trim := func(p string) string { return strings.TrimRight(p, "ABCDEFGH") }
+	return t.from != "" && t.to != "" && trim(t.from) != trim(t.to)
+}
+
+// transfers computes the buffer of every change of a connection, scheduled
+// and adjusted by the delays, less any walk in between.
+func transfers(conn *api.Connection) []transfer {
+	var out []transfer
+	var prev *api.Section
+	var walk time.Duration
+	for i := range conn.Sections {
+		s := &conn.Sections[i]
+		if s.Walk != nil {
+			walk += time.Duration(s.Walk.Duration) * time.Second
+			continue
+		}
+		if s.Journey == nil {
+			continue
+		}
+		if prev != nil {
+			if t, ok := newTransfer(*prev, *s, walk); ok {
+				t.section = i
+				out = append(out, t)
+			}
+		}
+		prev, walk = s, 0
+	}
+	return out
+}
+
+// newTransfer grades the change from the arrival of in to the departure of
+// out. It fails when either time is unknown.
+func newTransfer(in, out api.Section, walk time.Duration) (transfer, bool) {
+	arr, err1 := parseAPITime(in.Arrival.Arrival)
+	dep, err2 := parseAPITime(out.Departure.Departure)
+	if err1 != nil || err2 != nil {
+		return transfer{}, false
+	}
+	t := transfer{station: out.Departure.Station.Name, scheduled: dep.Sub(arr) - walk}
+	expArr := expectedStopTime(arr, in.Arrival.Prognosis.Arrival, in.Arrival.Delay)
+	expDep := expectedStopTime(dep, out.Departure.Prognosis.Departure, out.Departure.Delay)
+	t.expected = expDep.Sub(expArr) - walk
+	if walk == 0 {
+		t.from, _ = stopPlatform(in.Arrival)
+		t.to, _ = stopPlatform(out.Departure)
+	}
+
+	need := minTransfer
+	if t.platformChange() {
+		need = minPlatformTransfer
+	}
+	switch {
+	case t.expected < 0 || in.Arrival.Cancelled || out.Departure.Cancelled:
+		t.risk = riskBroken
+	case t.expected < need || t.scheduled < need:
+		t.risk = riskTight
+	}
+	return t, true
+}
+
+// expectedStopTime returns the forecast time of a stop: the prognosis, else
+// the scheduled time plus the delay in minutes.
+func expectedStopTime(scheduled time.Time, prognosis string, delay int) time.Time {
+	if t, err := parseAPITime(prognosis); err == nil {
+		return t
+	}
+	return scheduled.Add(time.Duration(delay) * time.Minute)
+}
+
+// connectionRisk returns the riskiest change of a connection.
+func connectionRisk(conn *api.Connection) (transfer, bool) {
+	var worst transfer
+	for _, t := range transfers(conn) {
+		if t.risk > worst.risk {
+			worst = t
+		}
+	}
+	return worst, worst.risk > riskNone
+}
+
+// riskMarker is the plain text marker of a risk, for tables.
+func riskMarker(r transferRisk) string {
+	switch r {
+	case riskTight:
+		return "⚠"
+	case riskBroken:
+		return "✖"
+	}
+	return ""
+}
+
+// riskNote describes the risk of a change, e.g. "⚠ tight change at Olten".
+func riskNote(t transfer) string {
+	switch t.risk {
+	case riskTight:
+		return changedStyle.Render("⚠ tight change at " + t.station)
+	case riskBroken:
+		return brokenStyle.Render("✖ change at " + t.station + " likely missed")
+	}
+	return ""
+}
+
+// renderTransfer describes a change, e.g. "🔄 Change at Zürich HB: 5 min →
+// 1 min, platform 5 → 6 ⚠ tight".
+func renderTransfer(t transfer) string {
+	buffer := formatBuffer(t.scheduled)
+	if t.expected != t.scheduled {
+		buffer += " → " + formatBuffer(t.expected)
+	}
+	platform := ""
+	if t.platformChange() {
+		platform = fmt.Sprintf(", platform %s → %s", t.from, t.to)
+	}
+	marker := ""
+	switch t.risk {
+	case riskTight:
+		marker = " " + changedStyle.Render("⚠ tight")
+	case riskBroken:
+		marker = " " + brokenStyle.Render("✖ likely missed")
+	}
+	return fmt.Sprintf("🔄 Change at %s: %s%s%s\n", t.station, buffer, platform, marker)
+}
+
+// formatBuffer renders a transfer time in whole minutes, e.g. "-2 min".
+func formatBuffer(d time.Duration) string {
+	return fmt.Sprintf("%d min", int(d.Minutes()))
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+)
+
+func TestTransfers(t *testing.T) {
+	conn := loadConn(t)
+	ts := transfers(conn)
+	if len(ts) != 1 {
+		t.Fatalf("expected one change, got %d", len(ts))
+	}
+	tr := ts[0]
+	if tr.station != "Zürich HB" || tr.scheduled != 5*time.Minute || !tr.platformChange() || tr.risk != riskNone {
+		t.Errorf("unexpected change %+v", tr)
+	}
+
+	// a delay of 4 minutes leaves one minute to change platforms
+	conn.Sections[0].Arrival.Delay = 4
+	tr = transfers(conn)[0]
+	if tr.expected != time.Minute || tr.risk != riskTight {
+		t.Errorf("expected a tight change, got %+v", tr)
+	}
+	if got := renderTransfer(tr); got != "🔄 Change at Zürich HB: 5 min → 1 min, platform 5 → 6 ⚠ tight\n" {
+		t.Errorf("unexpected transfer line %q", got)
+	}
+
+	// a prognosis wins over the delay
+	conn.Sections[0].Arrival.Prognosis.Arrival = "2024-01-01T14:07:00+01:00"
+	if tr = transfers(conn)[0]; tr.risk != riskBroken {
+		t.Errorf("expected a missed change, got %+v", tr)
+	}
+}
+
+func TestTransferWalk(t *testing.T) {
+	conn := &api.Connection{Sections: []api.Section{
+		{Journey: &api.Journey{}, Arrival: api.Stop{Arrival: "2024-01-01T12:00:00+01:00", Platform: "3"}},
+		{Walk: &api.Walk{Duration: 240}},
+		{Journey: &api.Journey{}, Departure: api.Stop{Departure: "2024-01-01T12:08:00+01:00", Platform: "B", Station: api.Location{Name: "Bern, Bahnhof"}}},
+	}}
+	ts := transfers(conn)
+	if len(ts) != 1 || ts[0].section != 2 || ts[0].scheduled != 4*time.Minute || ts[0].platformChange() || ts[0].risk != riskNone {
+		t.Errorf("expected the walk to be taken off the buffer, got %+v", ts)
+	}
+}
+
+func TestConnectionRiskMarkers(t *testing.T) {
+	conn := loadConn(t)
+	conn.Sections[0].Arrival.Delay = 4
+	details := renderConnectionDetails(conn)
+	for _, want := range []string{"1 change, ⚠ tight change at Zürich HB", "Change at Zürich HB: 5 min → 1 min"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("details miss %q:\n%s", want, details)
+		}
+	}
+	row := buildConnectionsTable(&api.ConnectionsResponse{Connections: []api.Connection{*conn}}, 2, 0).Rows()[0]
+	if row[4] != "1 change ⚠" {
+		t.Errorf("expected a risk marker in the changes column, got %q", row[4])
+	}
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openTrip shows the following stops of the departure selected on the
+// stationboard.
+func (m *Model) openTrip() {
+	if m.stationboard == nil {
+		return
+	}
+	idx := m.sbTable.Cursor()
+	if idx < 0 || idx >= len(m.stationboard.Stationboard) {
+		return
+	}
+	m.trip = &m.stationboard.Stationboard[idx]
+	m.tripCursor = 0
+	m.status = ""
+	m.state = stateShowTrip
+}
+
+// tripStops returns the stops of the trip shown in stateShowTrip.
+func (m *Model) tripStops() []api.Stop {
+	return tripStops(m.trip, m.stationboard != nil && m.stationboard.Arrivals)
+}
+
+// searchToStop starts a connection search from the stationboard station to
+// the selected stop of the trip, at the departure time of the train. On an
+// arrival board it searches from the stop to the station instead.
+func (m *Model) searchToStop() tea.Cmd {
+	stops := m.tripStops()
+	if m.stationboard == nil || m.tripCursor >= len(stops) {
+		return nil
+	}
+	m.stopLive()
+	stop := stops[m.tripCursor]
+	from, to := m.stationboard.EntryStation(*m.trip).Name, stop.Station.Name
+	departure := m.trip.Stop.Departure
+	if m.stationboard.Arrivals {
+		from, to = to, from
+		departure = stop.Departure
+	}
+	t, err := parseAPITime(departure)
+	if err != nil {
+		return nil
+	}
+
+	m.resetConnectionInputs()
+	m.selectedStation = nil
+	m.boardStations = nil
+	m.fromStation = &api.Location{Name: from}
+	m.toStation = &api.Location{Name: to}
+	m.fromInput.SetValue(from)
+	m.toInput.SetValue(to)
+	m.lastSearchTime = t
+	m.lastSearchArrival = false
+	m.isLoading = true
+	m.state = stateLoadingConnections
+	ctx, id := m.startFetch()
+	date := t.Format("2006-01-02")
+	tm := t.Format("15:04")
+	return tea.Batch(m.api.FetchConnectionsAtCmd(ctx, id, from, to, nil, date, tm, m.connectionOptions(false)), m.spinner.Tick)
+}
+
+// tripLayout controls how renderTrip lays out a trip.
+type tripLayout struct {
+	cursor   int // highlighted stop
+	width    int
+	maxRows  int  // 0 shows all stops
+	arrivals bool // the trip was opened from an arrival board
+}
+
+// renderTrip renders a departure from station and the stops it serves
+// afterwards with their scheduled and expected times, delays and platforms.
+// For an arrival it lists the stops before station instead.
+func renderTrip(station string, e *api.StationboardEntry, l tripLayout) string {
+	if e == nil {
+		return ""
+	}
+	name := func(n string) string {
+		if l.width <= 0 {
+			return n
+		}
+		return truncate(n, max(l.width-35, 12))
+	}
+
+	var s strings.Builder
+	verb, scheduled, expected := "Departs", e.Stop.Departure, e.Stop.Prognosis.Departure
+	if l.arrivals {
+		verb, scheduled, expected = "Arrives", e.Stop.Arrival, e.Stop.Prognosis.Arrival
+	}
+	if origin := e.Origin(); l.arrivals && origin != "" {
+		s.WriteString(fmt.Sprintf("🚆 %s%s from %s\n", e.Category, e.Number, name(origin)))
+	} else {
+		s.WriteString(fmt.Sprintf("🚆 %s%s to %s\n", e.Category, e.Number, name(e.To)))
+	}
+	at := expectedTime(scheduled, expected)
+	if e.Stop.Cancelled {
+		at = cancelledStyle.Render(strike(formatISOTime(scheduled))) + " ✖ cancelled"
+	}
+	s.WriteString(fmt.Sprintf("%s %s %s", verb, name(station), at))
+	if label := platformLabel(e.Stop); label != "" {
+		s.WriteString(", Platform " + label)
+	}
+	if e.Operator != "" {
+		s.WriteString(" (" + e.Operator + ")")
+	}
+	s.WriteString("\n" + ruler("─", l.width) + "\n")
+
+	stops := tripStops(e, l.arrivals)
+	if len(stops) == 0 {
+		s.WriteString("No stop information for this train\n")
+		return s.String()
+	}
+
+	// keep the cursor in view
+	start, end := 0, len(stops)
+	if l.maxRows > 0 && len(stops) > l.maxRows {
+		start = max(0, min(l.cursor-l.maxRows/2, len(stops)-l.maxRows))
+		end = start + l.maxRows
+	}
+	for i := start; i < end; i++ {
+		stop := stops[i]
+		marker := "  "
+		if i == l.cursor {
+			marker = "> "
+		}
+		// times at which the train can be boarded before an arrival
+		scheduled, expected := stop.Arrival, stop.Prognosis.Arrival
+		if scheduled == "" || (l.arrivals && stop.Departure != "") {
+			scheduled, expected = stop.Departure, stop.Prognosis.Departure
+		}
+		timeStr := fmt.Sprintf("%-13s", expectedTime(scheduled, expected))
+		stopName := name(stop.Station.Name)
+		if stop.Cancelled {
+			stopName = cancelledStyle.Render(strike(stopName)) + " (no stop)"
+		}
+		line := fmt.Sprintf("%s%s %-4s %s", marker, timeStr, formatDelay(stop.Delay), stopName)
+		if label := platformLabel(stop); label != "" {
+			line += ", Platform " + label
+		}
+		s.WriteString(line + "\n")
+	}
+	if start > 0 || end < len(stops) {
+		s.WriteString(fmt.Sprintf("(stops %d-%d of %d)\n", start+1, end, len(stops)))
+	}
+	return s.String()
+}
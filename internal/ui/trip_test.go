@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func openFixtureBoard(t *testing.T) *Model {
+	t.Helper()
+	m := newFixtureModel(t)
+	cmd := m.openFavorite(store.Favorite{Name: "Chur", Stations: []string{"Chur"}})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if m.state != stateShowStationboard {
+		t.Fatalf("expected stationboard, got %v", m.state)
+	}
+	return m
+}
+
+func TestStationboardOpenTrip(t *testing.T) {
+	m := openFixtureBoard(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != stateShowTrip || m.trip == nil {
+		t.Fatalf("expected trip view, got %v", m.state)
+	}
+
+	out := m.View()
+	for _, want := range []string{"RE1234 to Basel SBB", "> 12:39 → 12:41", "+2", "Platform 3 (was 2)", "Basel SBB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trip view misses %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "> 12:30") {
+		t.Errorf("the departure station must not be listed as a following stop")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != stateShowStationboard {
+		t.Errorf("esc should return to the stationboard, got %v", m.state)
+	}
+}
+
+func TestTripSearchToStop(t *testing.T) {
+	m := openFixtureBoard(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for i := 0; i < 5; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if m.tripCursor != 2 {
+		t.Fatalf("cursor should stop at the last stop, got %d", m.tripCursor)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil || m.state != stateLoadingConnections {
+		t.Fatalf("expected a connection search, got state %v", m.state)
+	}
+	if m.fromStation.Name != "Chur" || m.toStation.Name != "Basel SBB" || m.selectedStation != nil {
+		t.Errorf("unexpected search %+v → %+v", m.fromStation, m.toStation)
+	}
+	if got := m.lastSearchTime.Format("15:04"); got != "12:30" {
+		t.Errorf("search should start at the departure, got %s", got)
+	}
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if m.state != stateShowConnections {
+		t.Errorf("expected connections, got %v", m.state)
+	}
+}
+
+func TestTripStops(t *testing.T) {
+	e := &api.StationboardEntry{
+		Stop: api.Stop{Station: api.Location{ID: "1", Name: "Chur"}},
+		PassList: []api.Stop{
+			{Station: api.Location{ID: "1", Name: "Chur"}},
+			{Station: api.Location{ID: "2", Name: "Landquart"}},
+		},
+	}
+	if stops := tripStops(e, false); len(stops) != 1 || stops[0].Station.Name != "Landquart" {
+		t.Errorf("unexpected stops %v", stops)
+	}
+	arrival := &api.StationboardEntry{
+		Stop: api.Stop{Station: api.Location{ID: "2", Name: "Landquart"}},
+		PassList: []api.Stop{
+			{Station: api.Location{ID: "1", Name: "Chur"}},
+			{Station: api.Location{ID: "2", Name: "Landquart"}},
+		},
+	}
+	if stops := tripStops(arrival, true); len(stops) != 1 || stops[0].Station.Name != "Chur" {
+		t.Errorf("arrivals should list the stops before the station, got %v", stops)
+	}
+	if tripStops(nil, false) != nil {
+		t.Errorf("expected no stops without a departure")
+	}
+	if !strings.Contains(renderTrip("Chur", &api.StationboardEntry{Category: "S", Number: "1"}, tripLayout{}), "No stop information for this train") {
+		t.Errorf("expected a note for departures without stops")
+	}
+}
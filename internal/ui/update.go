@@ -1,14 +1,17 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
 	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mdp/qrterminal/v3"
@@ -24,6 +27,255 @@ func (m *Model) viaNames() []string {
 	return names
 }
 
+// newViaInput creates an empty textinput for a via station.
+func (m *Model) newViaInput() textinput.Model {
+	vi := textinput.New()
+	vi.Placeholder = "Via station"
+	vi.CharLimit = 50
+	vi.Width = m.inputWidth()
+	return vi
+}
+
+// openFavorite starts the search stored in a favorite: the stationboard of a
+// station or the connections of a route, both from now on.
+func (m *Model) openFavorite(fav store.Favorite) tea.Cmd {
+	m.resetConnectionInputs()
+	m.selectedStation = nil
+	m.boardStations = nil
+	m.startWalk(fav)
+	m.lastSearchTime = time.Now().Truncate(time.Minute)
+	m.lastSearchArrival = false
+	m.isLoading = true
+	m.state = stateLoadingConnections
+	if !fav.IsRoute() {
+		m.selectedStation = &api.Location{Name: fav.From()}
+		m.boardStations = []string{fav.From()}
+		return m.fetchBoardFromNow()
+	}
+	m.fromStation = &api.Location{Name: fav.From()}
+	m.toStation = &api.Location{Name: fav.To()}
+	for _, v := range fav.Via() {
+		vi := m.newViaInput()
+		vi.SetValue(v)
+		m.viaInputs = append(m.viaInputs, vi)
+		m.viaStations = append(m.viaStations, &api.Location{Name: v})
+	}
+	ctx, id := m.startFetch()
+	return tea.Batch(m.api.FetchConnectionsCmd(ctx, id, fav.From(), fav.To(), fav.Via(), m.connectionOptions(false)), m.spinner.Tick)
+}
+
+// chooseStation assigns a resolved station to the input being edited and
+// moves on to the next step of the search setup.
+func (m *Model) chooseStation(loc *api.Location) {
+	m.resetAutocomplete()
+	switch m.state {
+	case stateStationInput:
+		m.selectedStation = loc
+		m.stationInput.Blur()
+		m.prepareDateTime(false, false)
+		m.returnState = stateStationInput
+		m.state = stateDateTimeInput
+	case stateConnectionInputFrom:
+		m.fromStation = loc
+		m.fromInput.Blur()
+		if m.toStation != nil {
+			m.cursor = 0
+			m.state = stateConnectionReady
+			return
+		}
+		m.toInput.Focus()
+		m.state = stateConnectionInputTo
+	case stateConnectionInputTo:
+		m.toStation = loc
+		m.toInput.Blur()
+		if m.fromStation != nil {
+			m.cursor = len(m.viaInputs) + 2
+			m.state = stateConnectionReady
+			return
+		}
+		m.state = stateConnectionInputFrom
+	case stateConnectionInputVia:
+		m.viaStations[m.viaIndex] = loc
+		m.viaInputs[m.viaIndex].SetValue(loc.Name)
+		m.viaInputs[m.viaIndex].Blur()
+		m.cursor = m.viaIndex + 1
+		m.state = stateConnectionReady
+	}
+}
+
+// resolveStation looks up the station typed into the active input without
+// blocking the UI. The result arrives as api.LocationsMsg.
+func (m *Model) resolveStation(query string) tea.Cmd {
+	m.cancelResolve()
+	m.resetAutocomplete()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.resolving = resolveState{state: m.state, query: query, cancel: cancel}
+	m.isLoading = true
+	return tea.Batch(m.api.SearchStationsCmd(ctx, query), m.spinner.Tick)
+}
+
+// startFetch cancels the running stationboard or connections fetch, if any,
+// and returns the context and ID for the next one.
+func (m *Model) startFetch() (context.Context, int) {
+	if m.fetch.cancel != nil {
+		m.fetch.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.fetch.id++
+	m.fetch.cancel = cancel
+	return ctx, m.fetch.id
+}
+
+// reloadConnections searches the current route again for the last searched
+// time, or from now on if none was chosen.
+func (m *Model) reloadConnections() tea.Cmd {
+	m.isLoading = true
+	m.state = stateLoadingConnections
+	if !m.lastSearchTime.IsZero() {
+		date := m.lastSearchTime.Format("2006-01-02")
+		tm := m.lastSearchTime.Format("15:04")
+		ctx, id := m.startFetch()
+		return tea.Batch(m.api.FetchConnectionsAtCmd(ctx, id, m.fromStation.Name, m.toStation.Name, m.viaNames(), date, tm, m.connectionOptions(m.lastSearchArrival)), m.spinner.Tick)
+	}
+	m.lastSearchTime = time.Now().Truncate(time.Minute)
+	m.lastSearchArrival = false
+	ctx, id := m.startFetch()
+	return tea.Batch(m.api.FetchConnectionsCmd(ctx, id, m.fromStation.Name, m.toStation.Name, m.viaNames(), m.connectionOptions(false)), m.spinner.Tick)
+}
+
+// cancelFetch aborts the running fetch so its result is never applied.
+func (m *Model) cancelFetch() {
+	if m.fetch.cancel != nil {
+		m.fetch.cancel()
+	}
+	m.fetch = fetchState{id: m.fetch.id + 1}
+	m.isLoading = false
+	m.returnToDetails = false
+}
+
+// finishFetch reports whether a fetch result with the given ID belongs to the
+// current request and releases its context.
+func (m *Model) finishFetch(id int) bool {
+	if id != m.fetch.id {
+		return false
+	}
+	if m.fetch.cancel != nil {
+		m.fetch.cancel()
+		m.fetch.cancel = nil
+	}
+	return true
+}
+
+// cancelResolve aborts a running station lookup.
+func (m *Model) cancelResolve() {
+	if m.resolving.cancel != nil {
+		m.resolving.cancel()
+		m.isLoading = false
+	}
+	m.resolving = resolveState{}
+}
+
+// handleLocations continues in the input that started the lookup: an exact
+// match is taken directly, otherwise the suggestions are offered. Results of
+// cancelled or superseded lookups are dropped.
+func (m *Model) handleLocations(msg api.LocationsMsg) {
+	if m.resolving.cancel == nil || msg.Query != m.resolving.query || m.state != m.resolving.state {
+		return
+	}
+	m.cancelResolve()
+
+	errState := stateShowConnections
+	if m.state == stateStationInput {
+		errState = stateShowStationboard
+	}
+	if msg.Err != nil {
+		m.err = fmt.Errorf("failed to search stations: %v", msg.Err)
+		m.state = errState
+		return
+	}
+	if len(msg.Stations) == 0 {
+		m.err = fmt.Errorf("no stations found for '%s'", msg.Query)
+		m.state = errState
+		return
+	}
+	if exactMatch := api.FindExactMatch(msg.Query, msg.Stations); exactMatch != nil {
+		m.chooseStation(exactMatch)
+		return
+	}
+
+	m.suggestions = msg.Stations
+	m.cursor = 0
+	switch m.state {
+	case stateStationInput:
+		m.stationInput.Blur()
+		m.state = stateStationSuggestions
+	case stateConnectionInputFrom:
+		m.fromInput.Blur()
+		m.state = stateConnectionFromSuggestions
+	case stateConnectionInputTo:
+		m.toInput.Blur()
+		m.state = stateConnectionToSuggestions
+	case stateConnectionInputVia:
+		m.viaInputs[m.viaIndex].Blur()
+		m.state = stateConnectionViaSuggestions
+	}
+}
+
+// recordSearch remembers a successful search in the history. Failing to write
+// the history file is not worth interrupting the user for.
+func (m *Model) recordSearch(stations []string) {
+	m.store.History.Record(stations, time.Now())
+	_ = m.store.History.Save()
+}
+
+// recallStation cycles the given station input through previously searched
+// stations matching the text typed so far. It reports whether the key was
+// consumed; any other key ends the recall.
+func (m *Model) recallStation(msg tea.KeyMsg, input *textinput.Model) bool {
+	older := key.Matches(msg, m.keys.RecallOlder)
+	newer := key.Matches(msg, m.keys.RecallNewer)
+	if !older && !newer {
+		m.recall = recallState{}
+		return false
+	}
+	if m.recall.input != input {
+		m.recall = recallState{
+			input:   input,
+			matches: fuzzyMatch(m.store.History.Stations(), input.Value()),
+			index:   -1,
+		}
+	}
+	n := len(m.recall.matches)
+	if n == 0 {
+		return true
+	}
+	switch {
+	case older:
+		m.recall.index = (m.recall.index + 1) % n
+	case m.recall.index < 0:
+		m.recall.index = n - 1
+	default:
+		m.recall.index = (m.recall.index - 1 + n) % n
+	}
+	input.SetValue(m.recall.matches[m.recall.index])
+	input.CursorEnd()
+	return true
+}
+
+// startFavoriteNaming asks for a nickname before saving the given stations
+// as a favorite.
+func (m *Model) startFavoriteNaming(stations []string) {
+	m.favoriteDraft = store.Favorite{Stations: stations}
+	if len(stations) == 1 && strings.EqualFold(stations[0], m.walk.station) {
+		m.favoriteDraft.WalkMinutes = m.walk.minutes
+	}
+	m.favoriteInput.SetValue(strings.Join(stations, " → "))
+	m.favoriteInput.CursorEnd()
+	m.favoriteInput.Focus()
+	m.favoriteReturn = m.state
+	m.state = stateFavoriteName
+}
+
 func (m *Model) Init() tea.Cmd {
 	return m.spinner.Tick
 }
@@ -38,21 +290,47 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Global keybindings
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		if key.Matches(keyMsg, m.keys.Quit) {
+		if key.Matches(keyMsg, m.activeKeys().Quit) {
 			return m, tea.Quit
 		}
 	}
 
+	// While a station lookup runs only Esc (cancel) is accepted.
+	if m.resolving.cancel != nil {
+		switch msg := msg.(type) {
+		case spinner.TickMsg:
+			return m, cmd
+		case tea.KeyMsg:
+			if key.Matches(msg, m.keys.Back) {
+				m.cancelResolve()
+			}
+			return m, nil
+		}
+	}
+
 	// Handle async messages
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = msg.Width
+		m.resize()
+		return m, nil
+
+	case api.LocationsMsg:
+		m.handleLocations(msg)
+		return m, nil
+
 	case api.ConnectionsMsg:
+		if !m.finishFetch(msg.ID) {
+			return m, nil
+		}
 		m.isLoading = false
-		if msg.Connections != nil && m.lastSearchArrival && !m.lastSearchTime.IsZero() {
-			msg.Connections.Connections = tailUntilClosestArrival(msg.Connections.Connections, m.lastSearchTime)
+		m.status = ""
+		if msg.Err == nil && msg.Connections != nil && m.fromStation != nil && m.toStation != nil {
+			stations := append([]string{m.fromStation.Name}, m.viaNames()...)
+			m.recordSearch(append(stations, m.toStation.Name))
 		}
-		m.connections = msg.Connections
-		m.connTable = buildConnectionsTable(msg.Connections)
-		m.connTable.Focus()
+		m.showConnections(msg.Connections)
 		m.err = msg.Err
 		if m.returnToDetails {
 			m.returnToDetails = false
@@ -63,17 +341,40 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateShowConnections
 			}
 		} else {
-			m.cursor = 0
 			m.state = stateShowConnections
 		}
 		return m, nil
 
+	case connectionsPageMsg:
+		return m, m.handleConnectionsPage(msg)
+
+	case autocompleteTickMsg, autocompleteMsg:
+		return m, m.handleAutocompleteMsg(msg)
+
 	case api.StationboardMsg:
+		if !m.finishFetch(msg.ID) {
+			return m, nil
+		}
 		m.isLoading = false
-		m.stationboard = msg.Stationboard
+		m.status = ""
+		if msg.Err == nil && msg.Stationboard != nil {
+			m.recordBoard(msg.Stationboard)
+		}
+		m.showStationboard(msg.Stationboard)
 		m.err = msg.Err
 		m.state = stateShowStationboard
 		return m, nil
+
+	case stationboardPageMsg:
+		m.handleStationboardPage(msg)
+		return m, nil
+
+	case liveTickMsg:
+		return m, m.handleLiveTick(msg)
+
+	case liveBoardMsg:
+		m.handleLiveBoard(msg)
+		return m, nil
 	}
 
 	switch m.state {
@@ -85,19 +386,41 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.cursor--
 				}
 			case key.Matches(keyMsg, m.keys.Down):
-				if m.cursor < len(m.choices)-1 {
+				if m.cursor < len(m.menuItems())-1 {
 					m.cursor++
 				}
+			case key.Matches(keyMsg, m.keys.DelFavorite):
+				if idx := m.cursor - len(m.choices); idx >= 0 && idx < len(m.store.Favorites.Items) {
+					m.store.Favorites.Remove(idx)
+					if err := m.store.Favorites.Save(); err != nil {
+						m.status = fmt.Sprintf("❌ Could not save favorites: %v", err)
+					}
+					if m.cursor >= len(m.menuItems()) {
+						m.cursor = len(m.menuItems()) - 1
+					}
+				}
+			case key.Matches(keyMsg, m.keys.Filter):
+				m.openFilter()
 			case key.Matches(keyMsg, m.keys.Enter):
 				selectedOption := m.cursor
 				m.cursor = 0
-				if selectedOption == 0 {
+				m.status = ""
+				if idx := selectedOption - len(m.choices); idx >= 0 && idx < len(m.store.Favorites.Items) {
+					return m, m.openFavorite(m.store.Favorites.Items[idx])
+				}
+				switch selectedOption {
+				case menuTimetable:
+					m.boardStations = nil
+					m.addingStation = false
+					m.walk = walkState{}
 					m.stationInput.Focus()
 					m.state = stateStationInput
-				} else if selectedOption == 1 {
+				case menuConnection:
 					m.fromInput.Focus()
 					m.state = stateConnectionInputFrom
-				} else {
+				case menuRecentSearches:
+					m.state = stateRecentSearches
+				case menuRandom:
 					m.isLoading = true
 					m.state = stateLoadingConnections
 					stations, err := api.RandomStations(0)
@@ -112,7 +435,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					via := stations[1 : len(stations)-1]
 					m.viaInputs = nil
 					m.viaStations = nil
-					return m, tea.Batch(m.api.FetchConnectionsCmd(m.fromStation.Name, m.toStation.Name, via), m.spinner.Tick)
+					ctx, id := m.startFetch()
+					return m, tea.Batch(m.api.FetchConnectionsCmd(ctx, id, m.fromStation.Name, m.toStation.Name, via, m.connectionOptions(false)), m.spinner.Tick)
 				}
 			}
 		}
@@ -120,42 +444,30 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateStationInput:
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.Back) {
 			m.stationInput.Blur()
+			if m.addingStation {
+				// keep the board the station was going to be added to
+				m.addingStation = false
+				m.state = stateShowStationboard
+				return m, nil
+			}
 			m.cursor = 0
 			m.state = stateMenu
 			return m, nil
 		}
-		m.stationInput, cmd = m.stationInput.Update(msg)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.recallStation(keyMsg, &m.stationInput) {
+			m.resetAutocomplete()
+			return m, nil
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.handleAutocompleteKey(keyMsg) {
+			return m, nil
+		}
+		cmd = m.updateStationInput(&m.stationInput, msg)
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.Enter) {
 			query := strings.TrimSpace(m.stationInput.Value())
 			if query == "" {
 				break
 			}
-			suggestions, err := m.api.ValidateStation(query)
-			if err != nil {
-				m.err = fmt.Errorf("failed to search stations: %v", err)
-				m.state = stateShowStationboard
-				return m, nil
-			}
-
-			if len(suggestions) == 0 {
-				m.err = fmt.Errorf("no stations found for '%s'", query)
-				m.state = stateShowStationboard
-				return m, nil
-			}
-
-			if exactMatch := api.FindExactMatch(query, suggestions); exactMatch != nil {
-				m.selectedStation = exactMatch
-				m.stationInput.Blur()
-				m.prepareDateTime(false, false)
-				m.returnState = stateStationInput
-				m.state = stateDateTimeInput
-				return m, nil
-			}
-
-			m.suggestions = suggestions
-			m.cursor = 0
-			m.stationInput.Blur()
-			m.state = stateStationSuggestions
+			return m, tea.Batch(cmd, m.resolveStation(query))
 		}
 		return m, cmd
 
@@ -193,10 +505,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateMenu
 				return m, nil
 			case key.Matches(keyMsg, m.keys.AddVia):
-				vi := textinput.New()
-				vi.Placeholder = "Via station"
-				vi.CharLimit = 50
-				vi.Width = 40
+				vi := m.newViaInput()
 				vi.Focus()
 				m.fromInput.Blur()
 				m.viaInputs = append([]textinput.Model{vi}, m.viaInputs...)
@@ -218,42 +527,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
-		m.fromInput, cmd = m.fromInput.Update(msg)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.recallStation(keyMsg, &m.fromInput) {
+			m.resetAutocomplete()
+			return m, nil
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.handleAutocompleteKey(keyMsg) {
+			return m, nil
+		}
+		cmd = m.updateStationInput(&m.fromInput, msg)
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.Enter) {
 			query := strings.TrimSpace(m.fromInput.Value())
 			if query == "" {
 				break
 			}
-			suggestions, err := m.api.ValidateStation(query)
-			if err != nil {
-				m.err = fmt.Errorf("failed to search stations: %v", err)
-				m.state = stateShowConnections
-				return m, nil
-			}
-
-			if len(suggestions) == 0 {
-				m.err = fmt.Errorf("no stations found for '%s'", query)
-				m.state = stateShowConnections
-				return m, nil
-			}
-
-			if exactMatch := api.FindExactMatch(query, suggestions); exactMatch != nil {
-				m.fromStation = exactMatch
-				m.fromInput.Blur()
-				if m.toStation != nil {
-					m.cursor = 0
-					m.state = stateConnectionReady
-					return m, nil
-				}
-				m.toInput.Focus()
-				m.state = stateConnectionInputTo
-				return m, nil
-			}
-
-			m.suggestions = suggestions
-			m.cursor = 0
-			m.fromInput.Blur()
-			m.state = stateConnectionFromSuggestions
+			return m, tea.Batch(cmd, m.resolveStation(query))
 		}
 		return m, cmd
 
@@ -297,42 +584,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		m.toInput, cmd = m.toInput.Update(msg)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.recallStation(keyMsg, &m.toInput) {
+			m.resetAutocomplete()
+			return m, nil
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.handleAutocompleteKey(keyMsg) {
+			return m, nil
+		}
+		cmd = m.updateStationInput(&m.toInput, msg)
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.Enter) {
 			query := strings.TrimSpace(m.toInput.Value())
 			if query == "" {
 				break
 			}
-			suggestions, err := m.api.ValidateStation(query)
-			if err != nil {
-				m.err = fmt.Errorf("failed to search stations: %v", err)
-				m.state = stateShowConnections
-				return m, nil
-			}
-
-			if len(suggestions) == 0 {
-				m.err = fmt.Errorf("no stations found for '%s'", query)
-				m.state = stateShowConnections
-				return m, nil
-			}
-
-			if exactMatch := api.FindExactMatch(query, suggestions); exactMatch != nil {
-				m.toStation = exactMatch
-				if m.fromStation != nil {
-					m.toInput.Blur()
-					m.cursor = len(m.viaInputs) + 2
-					m.state = stateConnectionReady
-					return m, nil
-				}
-				m.toInput.Blur()
-				m.state = stateConnectionInputFrom
-				return m, nil
-			}
-
-			m.suggestions = suggestions
-			m.cursor = 0
-			m.toInput.Blur()
-			m.state = stateConnectionToSuggestions
+			return m, tea.Batch(cmd, m.resolveStation(query))
 		}
 		return m, cmd
 
@@ -397,10 +662,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateConnectionInputTo
 				return m, nil
 			case key.Matches(keyMsg, m.keys.AddVia):
-				vi := textinput.New()
-				vi.Placeholder = "Via station"
-				vi.CharLimit = 50
-				vi.Width = 40
+				vi := m.newViaInput()
 				vi.Focus()
 				idx := m.viaIndex + 1
 				m.viaInputs = append(m.viaInputs[:idx], append([]textinput.Model{vi}, m.viaInputs[idx:]...)...)
@@ -425,35 +687,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
-		m.viaInputs[m.viaIndex], cmd = m.viaInputs[m.viaIndex].Update(msg)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.recallStation(keyMsg, &m.viaInputs[m.viaIndex]) {
+			m.resetAutocomplete()
+			return m, nil
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.handleAutocompleteKey(keyMsg) {
+			return m, nil
+		}
+		cmd = m.updateStationInput(&m.viaInputs[m.viaIndex], msg)
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.Enter) {
 			query := strings.TrimSpace(m.viaInputs[m.viaIndex].Value())
 			if query == "" {
 				break
 			}
-			suggestions, err := m.api.ValidateStation(query)
-			if err != nil {
-				m.err = fmt.Errorf("failed to search stations: %v", err)
-				m.state = stateShowConnections
-				return m, nil
-			}
-			if len(suggestions) == 0 {
-				m.err = fmt.Errorf("no stations found for '%s'", query)
-				m.state = stateShowConnections
-				return m, nil
-			}
-			if exactMatch := api.FindExactMatch(query, suggestions); exactMatch != nil {
-				m.viaStations[m.viaIndex] = exactMatch
-				m.viaInputs[m.viaIndex].SetValue(exactMatch.Name)
-				m.viaInputs[m.viaIndex].Blur()
-				m.cursor = m.viaIndex + 1
-				m.state = stateConnectionReady
-				return m, nil
-			}
-			m.suggestions = suggestions
-			m.cursor = 0
-			m.viaInputs[m.viaIndex].Blur()
-			m.state = stateConnectionViaSuggestions
+			return m, tea.Batch(cmd, m.resolveStation(query))
 		}
 		return m, cmd
 
@@ -496,7 +743,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.cursor--
 				}
 			case key.Matches(keyMsg, m.keys.Down):
-				max := len(m.viaInputs) + 2
+				max := len(m.viaInputs) + 3
 				if m.cursor < max {
 					m.cursor++
 				}
@@ -520,13 +767,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.returnState = stateConnectionReady
 					m.state = stateDateTimeInput
 					return m, nil
+				case m.cursor == len(m.viaInputs)+3:
+					m.openSearchOptions()
+					return m, nil
 				}
 			case key.Matches(keyMsg, m.keys.AddVia):
 				if m.cursor == 0 || (m.cursor >= 1 && m.cursor <= len(m.viaInputs)) {
-					vi := textinput.New()
-					vi.Placeholder = "Via station"
-					vi.CharLimit = 50
-					vi.Width = 40
+					vi := m.newViaInput()
 					vi.Focus()
 					idx := m.cursor
 					m.viaInputs = append(m.viaInputs[:idx], append([]textinput.Model{vi}, m.viaInputs[idx:]...)...)
@@ -590,9 +837,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					date := m.dateTime.Format("2006-01-02")
 					tm := m.dateTime.Format("15:04")
 					if m.selectedStation != nil {
-						return m, tea.Batch(m.api.FetchStationboardAtCmd(m.selectedStation.Name, date, tm), m.spinner.Tick)
+						// a chosen time is not about catching a train
+						m.walk.on = false
+						m.useBoardStation()
+						ctx, id := m.startFetch()
+						return m, tea.Batch(m.api.FetchStationboardsCmd(ctx, id, m.boardStations, date, tm, m.stationboardOptions()), m.spinner.Tick)
 					}
-					return m, tea.Batch(m.api.FetchConnectionsAtCmd(m.fromStation.Name, m.toStation.Name, m.viaNames(), date, tm, m.arrival), m.spinner.Tick)
+					ctx, id := m.startFetch()
+					return m, tea.Batch(m.api.FetchConnectionsAtCmd(ctx, id, m.fromStation.Name, m.toStation.Name, m.viaNames(), date, tm, m.connectionOptions(m.arrival)), m.spinner.Tick)
 				}
 			}
 		}
@@ -600,7 +852,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateLoadingConnections:
 		// Only handle spinner updates and cancellation
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.Back) {
-			m.isLoading = false
+			m.cancelFetch()
+			m.stopLive()
 			m.cursor = 0
 			m.resetConnectionInputs()
 			m.state = stateMenu
@@ -611,33 +864,65 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateShowStationboard:
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			switch {
+			case key.Matches(keyMsg, m.keys.Enter) && m.err == nil && m.stationboard != nil && len(m.stationboard.Stationboard) > 0:
+				m.openTrip()
 			case key.Matches(keyMsg, m.keys.Back) || key.Matches(keyMsg, m.keys.Enter):
+				m.stopLive()
 				m.state = stateMenu
 				m.err = nil
 				m.selectedStation = nil
+				m.boardStations = nil
+				m.walk = walkState{}
 				m.cursor = 0
+				m.status = ""
+			case key.Matches(keyMsg, m.keys.Favorite):
+				switch {
+				case m.err != nil || len(m.boardStations) == 0:
+				case len(m.boardStations) > 1:
+					m.status = "Combined stationboards cannot be saved as favorites"
+				default:
+					m.startFavoriteNaming(m.boardStations)
+				}
+			case key.Matches(keyMsg, m.keys.AddVia):
+				if len(m.boardStations) > 0 && m.err == nil {
+					m.addBoardStation()
+				}
+			case key.Matches(keyMsg, m.keys.DelVia):
+				return m, m.removeBoardStation()
+			case key.Matches(keyMsg, m.keys.Up), key.Matches(keyMsg, m.keys.Down):
+				if m.stationboard == nil {
+					break
+				}
+				atEnd := m.sbTable.Cursor() >= len(m.stationboard.Stationboard)-1
+				m.sbTable, _ = m.sbTable.Update(msg)
+				if atEnd && key.Matches(keyMsg, m.keys.Down) {
+					return m, m.loadMoreDepartures()
+				}
 			case key.Matches(keyMsg, m.keys.Refresh):
-				if m.selectedStation != nil {
-					m.isLoading = true
-					m.state = stateLoadingConnections
-					if !m.lastSearchTime.IsZero() {
-						date := m.lastSearchTime.Format("2006-01-02")
-						tm := m.lastSearchTime.Format("15:04")
-						return m, tea.Batch(m.api.FetchStationboardAtCmd(m.selectedStation.Name, date, tm), m.spinner.Tick)
-					}
-					m.lastSearchTime = time.Now().Truncate(time.Minute)
-					m.lastSearchArrival = false
-					return m, tea.Batch(m.api.FetchStationboardCmd(m.selectedStation.Name), m.spinner.Tick)
+				if len(m.boardStations) > 0 {
+					return m, m.reloadStationboard()
+				}
+			case key.Matches(keyMsg, m.keys.Arrivals):
+				if len(m.boardStations) > 0 {
+					m.arrivals = !m.arrivals
+					return m, m.reloadStationboard()
+				}
+			case key.Matches(keyMsg, m.keys.Filter):
+				m.openFilter()
+			case key.Matches(keyMsg, m.keys.Live):
+				if len(m.boardStations) > 0 && m.err == nil {
+					return m, m.toggleLive()
 				}
 			case key.Matches(keyMsg, m.keys.DateTime):
-				if m.selectedStation != nil {
+				if len(m.boardStations) > 0 {
+					m.stopLive()
 					m.prepareDateTime(false, true)
 					m.returnState = stateShowStationboard
 					m.state = stateDateTimeInput
 					return m, nil
 				}
 			case key.Matches(keyMsg, m.keys.Left), key.Matches(keyMsg, m.keys.Right):
-				if m.selectedStation != nil {
+				if len(m.boardStations) > 0 {
 					var t time.Time
 					var err error
 					if key.Matches(keyMsg, m.keys.Right) {
@@ -650,71 +935,87 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 					}
 					if err == nil {
+						m.stopLive()
+						if key.Matches(keyMsg, m.keys.Left) {
+							// earlier departures are gone by the time you get there
+							m.walk.on = false
+						}
 						m.lastSearchTime = t
 						m.isLoading = true
 						m.state = stateLoadingConnections
 						date := t.Format("2006-01-02")
 						tm := t.Format("15:04")
-						return m, tea.Batch(m.api.FetchStationboardAtCmd(m.selectedStation.Name, date, tm), m.spinner.Tick)
+						ctx, id := m.startFetch()
+						return m, tea.Batch(m.api.FetchStationboardsCmd(ctx, id, m.boardStations, date, tm, m.stationboardOptions()), m.spinner.Tick)
 					}
 				}
 			}
 		}
 		return m, nil
 
+	case stateShowTrip:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Back):
+				m.state = stateShowStationboard
+			case key.Matches(keyMsg, m.keys.Up):
+				if m.tripCursor > 0 {
+					m.tripCursor--
+				}
+			case key.Matches(keyMsg, m.keys.Down):
+				if m.tripCursor < len(m.tripStops())-1 {
+					m.tripCursor++
+				}
+			case key.Matches(keyMsg, m.keys.Connect):
+				return m, m.searchToStop()
+			}
+		}
+		return m, nil
+
 	case stateShowConnections:
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			switch {
 			case key.Matches(keyMsg, m.keys.Back) || key.Matches(keyMsg, m.keys.Modify):
 				m.state = stateConnectionReady
 				m.cursor = 0
+				m.status = ""
+			case key.Matches(keyMsg, m.keys.Favorite):
+				if m.fromStation != nil && m.toStation != nil && m.err == nil {
+					stations := append([]string{m.fromStation.Name}, m.viaNames()...)
+					m.startFavoriteNaming(append(stations, m.toStation.Name))
+				}
 			case key.Matches(keyMsg, m.keys.Up), key.Matches(keyMsg, m.keys.Down):
+				if m.connections == nil {
+					break
+				}
+				atStart := m.connTable.Cursor() == 0
+				atEnd := m.connTable.Cursor() >= len(m.connections.Connections)-1
 				m.connTable, _ = m.connTable.Update(msg)
 				m.cursor = m.connTable.Cursor()
+				if atStart && key.Matches(keyMsg, m.keys.Up) {
+					return m, m.loadConnectionPage(false)
+				}
+				if atEnd && key.Matches(keyMsg, m.keys.Down) {
+					return m, m.loadConnectionPage(true)
+				}
 			case key.Matches(keyMsg, m.keys.Enter):
 				// Select connection for details
 				if m.connections != nil && m.connTable.Cursor() < len(m.connections.Connections) {
 					idx := m.connTable.Cursor()
 					m.selectedConnection = &m.connections.Connections[idx]
 					m.selectedConnectionIdx = idx
-					m.detailCursor = 0
+					m.detailCursor = journeySection(m.selectedConnection, -1, 1)
+					m.expandedStops = nil
 					m.state = stateShowConnectionDetails
 				}
 			case key.Matches(keyMsg, m.keys.Left), key.Matches(keyMsg, m.keys.Right):
-				if m.fromStation != nil && m.toStation != nil {
-					var t time.Time
-					var err error
-					if key.Matches(keyMsg, m.keys.Right) {
-						t, err = lastConnectionTime(m.connections, m.lastSearchArrival)
-					} else {
-						if m.lastSearchTime.IsZero() {
-							t = time.Now().Add(-10 * time.Minute)
-						} else {
-							t = m.lastSearchTime.Add(-10 * time.Minute)
-						}
-					}
-					if err == nil {
-						m.lastSearchTime = t
-						m.isLoading = true
-						m.state = stateLoadingConnections
-						date := t.Format("2006-01-02")
-						tm := t.Format("15:04")
-						return m, tea.Batch(m.api.FetchConnectionsAtCmd(m.fromStation.Name, m.toStation.Name, m.viaNames(), date, tm, m.lastSearchArrival), m.spinner.Tick)
-					}
-				}
+				return m, m.loadConnectionPage(key.Matches(keyMsg, m.keys.Right))
 			case key.Matches(keyMsg, m.keys.Refresh):
 				if m.fromStation != nil && m.toStation != nil {
-					m.isLoading = true
-					m.state = stateLoadingConnections
-					if !m.lastSearchTime.IsZero() {
-						date := m.lastSearchTime.Format("2006-01-02")
-						tm := m.lastSearchTime.Format("15:04")
-						return m, tea.Batch(m.api.FetchConnectionsAtCmd(m.fromStation.Name, m.toStation.Name, m.viaNames(), date, tm, m.lastSearchArrival), m.spinner.Tick)
-					}
-					m.lastSearchTime = time.Now().Truncate(time.Minute)
-					m.lastSearchArrival = false
-					return m, tea.Batch(m.api.FetchConnectionsCmd(m.fromStation.Name, m.toStation.Name, m.viaNames()), m.spinner.Tick)
+					return m, m.reloadConnections()
 				}
+			case key.Matches(keyMsg, m.keys.Filter):
+				m.openFilter()
 			case key.Matches(keyMsg, m.keys.DateTime):
 				if m.fromStation != nil && m.toStation != nil {
 					m.prepareDateTime(true, true)
@@ -733,6 +1034,23 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// back to list
 				m.state = stateShowConnections
 				m.selectedConnection = nil
+				m.status = ""
+			case key.Matches(keyMsg, m.keys.Up):
+				m.detailCursor = journeySection(m.selectedConnection, m.detailCursor, -1)
+			case key.Matches(keyMsg, m.keys.Down):
+				m.detailCursor = journeySection(m.selectedConnection, m.detailCursor, 1)
+			case key.Matches(keyMsg, m.keys.Stops):
+				if m.expandedStops == nil {
+					m.expandedStops = make(map[int]bool)
+				}
+				m.expandedStops[m.detailCursor] = !m.expandedStops[m.detailCursor]
+			case key.Matches(keyMsg, m.keys.Export):
+				path, err := exportConnectionICS(m.selectedConnection, ".")
+				if err != nil {
+					m.status = fmt.Sprintf("❌ Export failed: %v", err)
+				} else {
+					m.status = fmt.Sprintf("📅 Saved %s", path)
+				}
 			case key.Matches(keyMsg, m.keys.QR):
 				// generate QR
 				// 1) build deep link:
@@ -781,21 +1099,71 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateShowConnectionQR
 			case key.Matches(keyMsg, m.keys.Refresh):
 				if m.fromStation != nil && m.toStation != nil {
-					m.isLoading = true
 					m.returnToDetails = true
-					m.state = stateLoadingConnections
-					if !m.lastSearchTime.IsZero() {
-						date := m.lastSearchTime.Format("2006-01-02")
-						tm := m.lastSearchTime.Format("15:04")
-						return m, tea.Batch(m.api.FetchConnectionsAtCmd(m.fromStation.Name, m.toStation.Name, m.viaNames(), date, tm, m.lastSearchArrival), m.spinner.Tick)
-					}
-					m.lastSearchTime = time.Now().Truncate(time.Minute)
-					m.lastSearchArrival = false
-					return m, tea.Batch(m.api.FetchConnectionsCmd(m.fromStation.Name, m.toStation.Name, m.viaNames()), m.spinner.Tick)
+					return m, m.reloadConnections()
 				}
 			}
 		}
 		return m, nil
+	case stateRecentSearches:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Back):
+				m.cursor = menuRecentSearches
+				m.state = stateMenu
+				return m, nil
+			case key.Matches(keyMsg, m.keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(keyMsg, m.keys.Down):
+				if m.cursor < len(m.store.History.Searches)-1 {
+					m.cursor++
+				}
+			case key.Matches(keyMsg, m.keys.Enter):
+				if m.cursor < len(m.store.History.Searches) {
+					search := m.store.History.Searches[m.cursor]
+					m.cursor = 0
+					return m, m.openFavorite(search.Favorite())
+				}
+			}
+		}
+		return m, nil
+
+	case stateFavoriteName:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Back):
+				m.favoriteInput.Blur()
+				m.state = m.favoriteReturn
+				return m, nil
+			case key.Matches(keyMsg, m.keys.Enter):
+				m.favoriteDraft.Name = strings.TrimSpace(m.favoriteInput.Value())
+				if m.favoriteDraft.Name == "" {
+					m.favoriteDraft.Name = strings.Join(m.favoriteDraft.Stations, " → ")
+				}
+				m.store.Favorites.Add(m.favoriteDraft)
+				if err := m.store.Favorites.Save(); err != nil {
+					m.status = fmt.Sprintf("❌ Could not save favorites: %v", err)
+				} else {
+					m.status = fmt.Sprintf("★ Saved favorite %q", m.favoriteDraft.Name)
+				}
+				m.favoriteInput.Blur()
+				m.state = m.favoriteReturn
+				return m, nil
+			case !m.favoriteDraft.IsRoute() && (keyMsg.Type == tea.KeyUp || keyMsg.Type == tea.KeyDown):
+				// the arrow keys set the walk to the station, letters go to the nickname
+				walk := m.favoriteDraft.WalkMinutes + 1
+				if keyMsg.Type == tea.KeyDown {
+					walk -= 2
+				}
+				m.favoriteDraft.WalkMinutes = min(max(walk, 0), store.MaxWalkMinutes)
+				return m, nil
+			}
+		}
+		m.favoriteInput, cmd = m.favoriteInput.Update(msg)
+		return m, cmd
+
 	case stateShowConnectionQR:
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			if key.Matches(keyMsg, m.keys.Back) || key.Matches(keyMsg, m.keys.Enter) {
@@ -804,6 +1172,48 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		return m, nil
+
+	case stateTransportFilter:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Up):
+				if m.filter.cursor > 0 {
+					m.filter.cursor--
+				}
+			case key.Matches(keyMsg, m.keys.Down):
+				if m.filter.cursor < len(api.Transportations)-1 {
+					m.filter.cursor++
+				}
+			case key.Matches(keyMsg, m.keys.Toggle):
+				m.filter.chosen[m.filter.cursor] = !m.filter.chosen[m.filter.cursor]
+			case key.Matches(keyMsg, m.keys.Enter):
+				return m, m.applyFilter()
+			case key.Matches(keyMsg, m.keys.Back):
+				m.state = m.filter.returnState
+			}
+		}
+		return m, nil
+
+	case stateSearchOptions:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Up):
+				if m.options.cursor > 0 {
+					m.options.cursor--
+				}
+			case key.Matches(keyMsg, m.keys.Down):
+				if m.options.cursor < searchOptionRows-1 {
+					m.options.cursor++
+				}
+			case key.Matches(keyMsg, m.keys.Toggle):
+				m.toggleSearchOption()
+			case key.Matches(keyMsg, m.keys.Enter):
+				m.applySearchOptions()
+			case key.Matches(keyMsg, m.keys.Back):
+				m.state = stateConnectionReady
+			}
+		}
+		return m, nil
 	}
 
 	return m, cmd
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSaveRouteFavorite(t *testing.T) {
+	m := InitialModel()
+	m.state = stateShowConnections
+	m.fromStation = &api.Location{Name: "Bern"}
+	m.toStation = &api.Location{Name: "Zürich HB"}
+	m.viaStations = []*api.Location{{Name: "Olten"}}
+
+	newModel, _ := m.Update(tea.KeyMsg{Runes: []rune{'f'}, Type: tea.KeyRunes})
+	nm := newModel.(*Model)
+	if nm.state != stateFavoriteName {
+		t.Fatalf("expected %v got %v", stateFavoriteName, nm.state)
+	}
+	nm.favoriteInput.SetValue("office")
+	newModel, _ = nm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	nm = newModel.(*Model)
+	if nm.state != stateShowConnections {
+		t.Fatalf("expected %v got %v", stateShowConnections, nm.state)
+	}
+	fav, ok := nm.store.Favorites.Find("office")
+	if !ok || len(fav.Stations) != 3 || fav.Via()[0] != "Olten" {
+		t.Errorf("unexpected favorite %+v", fav)
+	}
+}
+
+func TestFavoriteNameTakesQ(t *testing.T) {
+	m := InitialModel()
+	m.state = stateShowStationboard
+	m.selectedStation = &api.Location{Name: "Bern"}
+	m.boardStations = []string{"Bern"}
+	m.stationboard = &api.StationboardResponse{Station: api.Location{Name: "Bern"}}
+	m.Update(tea.KeyMsg{Runes: []rune{'f'}, Type: tea.KeyRunes})
+	if m.state != stateFavoriteName {
+		t.Fatalf("expected %v got %v", stateFavoriteName, m.state)
+	}
+
+	m.favoriteInput.SetValue("")
+	for _, r := range "quai" {
+		m.Update(tea.KeyMsg{Runes: []rune{r}, Type: tea.KeyRunes})
+	}
+	if m.favoriteInput.Value() != "quai" {
+		t.Fatalf("q did not reach the nickname, got %q", m.favoriteInput.Value())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := m.store.Favorites.Find("quai"); !ok {
+		t.Errorf("nickname not saved, favorites %+v", m.store.Favorites.Items)
+	}
+	m.state = stateFavoriteName
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyCtrlC}, m.activeKeys().Quit) {
+		t.Error("ctrl+c should still quit")
+	}
+}
+
+func TestOpenFavoriteFromMenu(t *testing.T) {
+	m := InitialModel()
+	m.store.Favorites.Add(store.Favorite{Name: "office", Stations: []string{"Bern", "Olten", "Zürich HB"}})
+	m.cursor = len(m.choices)
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	nm := newModel.(*Model)
+	if nm.state != stateLoadingConnections || cmd == nil {
+		t.Fatalf("expected loading state with fetch command, got %v", nm.state)
+	}
+	if nm.fromStation.Name != "Bern" || nm.toStation.Name != "Zürich HB" || len(nm.viaInputs) != 1 {
+		t.Errorf("route not restored from favorite")
+	}
+}
+
+func TestDeleteFavoriteFromMenu(t *testing.T) {
+	m := InitialModel()
+	m.store.Favorites.Add(store.Favorite{Name: "home", Stations: []string{"Chur"}})
+	m.cursor = len(m.choices)
+	if !m.activeKeys().DelFavorite.Enabled() {
+		t.Fatal("delete favorite should be enabled on a favorite")
+	}
+
+	newModel, _ := m.Update(tea.KeyMsg{Runes: []rune{'x'}, Type: tea.KeyRunes})
+	nm := newModel.(*Model)
+	if len(nm.store.Favorites.Items) != 0 {
+		t.Errorf("favorite not deleted")
+	}
+	if nm.cursor != len(nm.choices)-1 {
+		t.Errorf("cursor should move back onto the menu, got %d", nm.cursor)
+	}
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"testing"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBackFromLoadingCancelsFetch(t *testing.T) {
+	m := newFixtureModel(t)
+	m.openFavorite(store.Favorite{Name: "office", Stations: []string{"Bern", "Zürich HB"}})
+	id := m.fetch.id
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != stateMenu || m.isLoading {
+		t.Fatalf("expected menu, got %v", m.state)
+	}
+	if m.fetch.cancel != nil || m.fetch.id == id {
+		t.Errorf("expected the request to be cancelled")
+	}
+
+	m.Update(api.ConnectionsMsg{ID: id, Connections: &api.ConnectionsResponse{}})
+	if m.state != stateMenu || m.connections != nil {
+		t.Errorf("late result of cancelled fetch was applied, state %v", m.state)
+	}
+}
+
+func TestStaleFetchResultDropped(t *testing.T) {
+	m := newFixtureModel(t)
+	fav := store.Favorite{Name: "home", Stations: []string{"Chur"}}
+	m.openFavorite(fav)
+	first := m.fetch.id
+	m.openFavorite(fav)
+
+	m.Update(api.StationboardMsg{ID: first, Stationboard: &api.StationboardResponse{Station: api.Location{Name: "Old"}}})
+	if m.stationboard != nil {
+		t.Fatalf("superseded result was applied")
+	}
+	m.Update(api.StationboardMsg{ID: m.fetch.id, Stationboard: &api.StationboardResponse{Station: api.Location{Name: "Chur"}}})
+	if m.stationboard == nil || m.state != stateShowStationboard {
+		t.Errorf("current result was not applied")
+	}
+}
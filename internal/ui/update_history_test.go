@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRecordSearchOnResults(t *testing.T) {
+	m := InitialModel()
+	m.selectedStation = &api.Location{Name: "Chur"}
+	m.Update(api.StationboardMsg{Stationboard: &api.StationboardResponse{Station: api.Location{Name: "Chur"}}})
+
+	m.fromStation = &api.Location{Name: "Bern"}
+	m.toStation = &api.Location{Name: "Zürich HB"}
+	m.Update(api.ConnectionsMsg{Connections: &api.ConnectionsResponse{}})
+
+	searches := m.store.History.Searches
+	if len(searches) != 2 || searches[0].Stations[1] != "Zürich HB" || searches[1].Stations[0] != "Chur" {
+		t.Errorf("unexpected history %+v", searches)
+	}
+}
+
+func TestRecallCyclesRecentStations(t *testing.T) {
+	m := InitialModel()
+	m.store.History.Record([]string{"Zürich HB"}, time.Now())
+	m.store.History.Record([]string{"Bern", "Zug"}, time.Now())
+	m.state = stateConnectionInputFrom
+	m.fromInput.Focus()
+	m.fromInput.SetValue("z")
+
+	ctrlP := tea.KeyMsg{Type: tea.KeyCtrlP}
+	m.Update(ctrlP)
+	if m.fromInput.Value() != "Zug" {
+		t.Fatalf("expected most recent match, got %q", m.fromInput.Value())
+	}
+	m.Update(ctrlP)
+	if m.fromInput.Value() != "Zürich HB" {
+		t.Fatalf("expected older match, got %q", m.fromInput.Value())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if m.fromInput.Value() != "Zug" {
+		t.Errorf("expected to cycle back, got %q", m.fromInput.Value())
+	}
+}
+
+func TestRerunRecentSearch(t *testing.T) {
+	for _, favorites := range []bool{false, true} {
+		m := InitialModel()
+		if favorites {
+			m.store.Favorites.Add(store.Favorite{Name: "home", Stations: []string{"Chur"}})
+		}
+		m.store.History.Record([]string{"Bern", "Zürich HB"}, time.Now())
+
+		for m.cursor < menuRecentSearches {
+			m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if m.state != stateRecentSearches {
+			t.Fatalf("favorites %v: expected recent searches from the menu, state %v", favorites, m.state)
+		}
+
+		m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		if m.state != stateMenu || m.menuItems()[m.cursor] != "Recent searches" {
+			t.Fatalf("favorites %v: expected the cursor back on the entry, got %d", favorites, m.cursor)
+		}
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		nm := newModel.(*Model)
+		if nm.state != stateLoadingConnections || cmd == nil {
+			t.Fatalf("favorites %v: expected rerun of the search, state %v", favorites, nm.state)
+		}
+		if nm.fromStation.Name != "Bern" || nm.toStation.Name != "Zürich HB" {
+			t.Errorf("favorites %v: search not restored", favorites)
+		}
+	}
+}
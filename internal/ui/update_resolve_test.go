@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+
+	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEnterResolvesStationAsync(t *testing.T) {
+	m := newFixtureModel(t)
+	m.state = stateStationInput
+	m.stationInput.SetValue("Chur")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.resolving.cancel == nil || !m.isLoading {
+		t.Fatalf("expected a background lookup with spinner")
+	}
+	if m.state != stateStationInput {
+		t.Fatalf("state changed before the lookup finished: %v", m.state)
+	}
+
+	m.Update(api.LocationsMsg{Query: "Chur", Stations: []api.Location{{Name: "Chur"}, {Name: "Chur West"}}})
+	if m.state != stateDateTimeInput || m.selectedStation == nil || m.selectedStation.Name != "Chur" {
+		t.Errorf("expected exact match to be chosen, state %v", m.state)
+	}
+	if m.isLoading {
+		t.Errorf("spinner still running")
+	}
+}
+
+func TestResolveOffersSuggestions(t *testing.T) {
+	m := newFixtureModel(t)
+	m.state = stateConnectionInputTo
+	m.toInput.Focus()
+	m.toInput.SetValue("Zür")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m.Update(api.LocationsMsg{Query: "Zür", Stations: []api.Location{{Name: "Zürich HB"}, {Name: "Zürich Altstetten"}}})
+	if m.state != stateConnectionToSuggestions || len(m.suggestions) != 2 {
+		t.Errorf("expected suggestions, state %v", m.state)
+	}
+}
+
+func TestEscCancelsResolve(t *testing.T) {
+	m := newFixtureModel(t)
+	m.state = stateConnectionInputFrom
+	m.fromInput.Focus()
+	m.fromInput.SetValue("Bern")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.resolving.cancel != nil || m.isLoading {
+		t.Fatalf("expected lookup to be cancelled")
+	}
+	if m.state != stateConnectionInputFrom {
+		t.Fatalf("esc should stay in the input, got %v", m.state)
+	}
+
+	m.Update(api.LocationsMsg{Query: "Bern", Stations: []api.Location{{Name: "Bern"}}})
+	if m.fromStation != nil || m.state != stateConnectionInputFrom {
+		t.Errorf("late result of cancelled lookup was applied")
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	api "SBBuddy/internal/api"
 )
@@ -13,20 +14,26 @@ func (m *Model) View() string {
 	case stateMenu:
 		s := "🚂 Swiss Transport Timetable\n\n"
 		s += "Select an option:\n\n"
-		for i, choice := range m.choices {
+		for i, choice := range m.menuItems() {
+			if i == len(m.choices) {
+				s += "\nFavorites:\n"
+			}
 			cursor := " "
 			if m.cursor == i {
 				cursor = ">"
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
+		if m.status != "" {
+			s += "\n" + m.status + "\n"
+		}
 		return s + helpView
 
 	case stateLoadingConnections:
 		return fmt.Sprintf("\n%s Loading...", m.spinner.View()) + helpView
 
 	case stateStationInput:
-		return "Enter station for timetable:\n\n" + m.stationInput.View() + helpView
+		return "Enter station for timetable:\n\n" + m.stationInput.View() + m.autocompleteView(&m.stationInput) + m.resolvingView() + helpView
 
 	case stateStationSuggestions:
 		s := "Multiple stations found. Select one:\n\n"
@@ -44,7 +51,7 @@ func (m *Model) View() string {
 		if m.fromStation != nil {
 			fromText = fmt.Sprintf(" (selected: %s)", m.fromStation.Name)
 		}
-		return fmt.Sprintf("Enter departure station%s:\n\n%s", fromText, m.fromInput.View()) + helpView
+		return fmt.Sprintf("Enter departure station%s:\n\n%s", fromText, m.fromInput.View()+m.autocompleteView(&m.fromInput)) + m.resolvingView() + helpView
 
 	case stateConnectionFromSuggestions:
 		s := fmt.Sprintf("Multiple departure stations found. Select one:\n\n")
@@ -58,7 +65,7 @@ func (m *Model) View() string {
 		return s + helpView
 
 	case stateConnectionInputTo:
-		return renderConnectionInputs(m, false) + helpView
+		return renderConnectionInputs(m, false) + m.resolvingView() + helpView
 
 	case stateConnectionToSuggestions:
 		s := "Multiple arrival stations found. Select one:\n\n"
@@ -72,7 +79,7 @@ func (m *Model) View() string {
 		return renderConnectionInputs(m, false) + "\n\n" + s + helpView
 
 	case stateConnectionInputVia:
-		return renderConnectionInputs(m, true) + helpView
+		return renderConnectionInputs(m, true) + m.resolvingView() + helpView
 
 	case stateConnectionViaSuggestions:
 		s := "Multiple via stations found. Select one:\n\n"
@@ -98,21 +105,38 @@ func (m *Model) View() string {
 		}
 		if m.stationboard == nil || len(m.stationboard.Stationboard) == 0 {
 			stationName := "Unknown"
-			if m.selectedStation != nil {
-				stationName = m.selectedStation.Name
+			if len(m.boardStations) > 0 {
+				stationName = strings.Join(m.boardStations, " + ")
 			}
-			return fmt.Sprintf("📋 No departures found for %s", stationName) + helpView
+			kind := "departures"
+			if m.arrivals {
+				kind = "arrivals"
+			}
+			return fmt.Sprintf("📋 No %s found for %s\n%s", kind, stationName, m.transportNote()) + helpView
 		}
-		table := renderStationboardTable(m.stationboard)
+		table := m.sbTable.View()
 		info := ""
 		if !m.lastSearchTime.IsZero() {
 			mode := "Depart"
-			if m.lastSearchArrival {
+			if m.stationboard.Arrivals {
 				mode = "Arrive"
 			}
 			info = fmt.Sprintf(" (%s %s %s)", mode, m.lastSearchTime.Format("Mon 02.01.2006"), m.lastSearchTime.Format("15:04"))
 		}
-		return fmt.Sprintf("📋 Stationboard for %s%s:\n\n%s", m.stationboard.Station.Name, info, table) + helpView
+		if m.live.on {
+			info = " (🔴 live, updated " + m.live.updated.Format("15:04:05") + ")"
+		}
+		title := "📋 Stationboard for"
+		if m.stationboard.Arrivals {
+			title = "📋 Arrivals at"
+		}
+		s := fmt.Sprintf("%s %s%s:\n%s%s%s\n%s", title, m.stationboard.Station.Name, info, m.transportNote(), m.walkNote(), cachedNotice(m.stationboard.CachedAt), table)
+		if m.paging.loading {
+			s += "\nLoading later departures..."
+		} else if m.status != "" {
+			s += "\n" + m.status
+		}
+		return s + helpView
 
 	case stateShowConnections:
 		if m.err != nil {
@@ -127,7 +151,7 @@ func (m *Model) View() string {
 			if m.toStation != nil {
 				toName = m.toStation.Name
 			}
-			return fmt.Sprintf("🔍 No connections found from %s to %s", fromName, toName) + helpView
+			return fmt.Sprintf("🔍 No connections found from %s to %s\n%s", fromName, toName, m.transportNote()+m.optionsNote()) + helpView
 		}
 
 		fromName := "Unknown"
@@ -146,26 +170,99 @@ func (m *Model) View() string {
 			}
 			info = fmt.Sprintf("%s %s %s", mode, m.lastSearchTime.Format("Mon 02.01.2006"), m.lastSearchTime.Format("15:04"))
 		}
-		s := renderConnectionsHeader(fromName, m.viaNames(), toName, info) + "\n\n"
+		s := renderConnectionsHeader(fromName, m.viaNames(), toName, info) + "\n" + m.transportNote() + m.optionsNote() + cachedNotice(m.connections.CachedAt) + "\n"
 		s += m.connTable.View()
+		if m.status != "" {
+			s += "\n" + m.status
+		}
 		return s + helpView
 
 	case stateShowConnectionDetails:
 		if m.selectedConnection == nil {
 			return "No connection selected" + helpView
 		}
-		return renderConnectionDetails(m.selectedConnection) + helpView
+		details := renderConnectionDetailsLayout(m.selectedConnection, detailsLayout{
+			cursor:   m.detailCursor,
+			expanded: m.expandedStops,
+			class:    m.travelClass,
+			width:    m.width,
+		})
+		if m.status != "" {
+			details += "\n" + m.status
+		}
+		return details + helpView
+
+	case stateShowTrip:
+		station, arrivals := "", false
+		if m.stationboard != nil {
+			station, arrivals = m.stationboard.Station.Name, m.stationboard.Arrivals
+		}
+		rows := len(m.tripStops())
+		return renderTrip(station, m.trip, tripLayout{
+			cursor:   m.tripCursor,
+			width:    m.width,
+			maxRows:  m.tableHeight(rows, 4),
+			arrivals: arrivals,
+		}) + helpView
+
+	case stateRecentSearches:
+		if len(m.store.History.Searches) == 0 {
+			return "🕘 No recent searches yet" + helpView
+		}
+		s := "🕘 Recent searches:\n\n"
+		for i, search := range m.store.History.Searches {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+			}
+			s += fmt.Sprintf("%s %s  %s\n", cursor, search.At.Local().Format("Mon 02.01. 15:04"), strings.Join(search.Stations, " → "))
+		}
+		return s + helpView
+
+	case stateFavoriteName:
+		s := fmt.Sprintf("Save favorite: %s\n\nNickname:\n\n%s", strings.Join(m.favoriteDraft.Stations, " → "), m.favoriteInput.View())
+		if !m.favoriteDraft.IsRoute() {
+			walk := "none"
+			if m.favoriteDraft.WalkMinutes > 0 {
+				walk = fmt.Sprintf("%d min", m.favoriteDraft.WalkMinutes)
+			}
+			s += fmt.Sprintf("\n\nWalk to the station: %s (↑/↓ to change)", walk)
+		}
+		return s + helpView
 
 	case stateShowConnectionQR:
 		return fmt.Sprintf(
 			"🔗 Scan to open in SBB timetable:\n\n%s", m.qrCode,
 		) + helpView
 
+	case stateTransportFilter:
+		return m.filterView() + helpView
+
+	case stateSearchOptions:
+		return m.searchOptionsView() + helpView
+
 	default:
 		return ""
 	}
 }
 
+// cachedNotice returns a warning line for results served from the offline
+// cache, or an empty line for live results.
+func cachedNotice(cachedAt time.Time) string {
+	if cachedAt.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("⚠️  Offline: showing cached data from %s\n", cachedAt.Local().Format("02.01. 15:04"))
+}
+
+// resolvingView shows the spinner while the entered station is looked up.
+func (m *Model) resolvingView() string {
+	if m.resolving.cancel == nil {
+		return ""
+	}
+	return fmt.Sprintf("\n\n%s Searching stations... (esc to cancel)", m.spinner.View())
+}
+
 func renderConnectionInputs(m *Model, editingVia bool) string {
 	var sb strings.Builder
 	if m.fromStation != nil {
@@ -176,7 +273,7 @@ func renderConnectionInputs(m *Model, editingVia bool) string {
 	for i, inp := range m.viaInputs {
 		label := fmt.Sprintf("Via %d: ", i+1)
 		if editingVia && m.viaIndex == i {
-			sb.WriteString(label + inp.View() + "\n")
+			sb.WriteString(label + inp.View() + m.autocompleteView(&m.viaInputs[i]) + "\n")
 			continue
 		}
 		if i < len(m.viaStations) && m.viaStations[i] != nil {
@@ -186,7 +283,7 @@ func renderConnectionInputs(m *Model, editingVia bool) string {
 		}
 	}
 	if m.state == stateConnectionInputTo {
-		sb.WriteString("To: " + m.toInput.View())
+		sb.WriteString("To: " + m.toInput.View() + m.autocompleteView(&m.toInput))
 	} else if m.toStation != nil {
 		sb.WriteString("To: " + m.toStation.Name)
 	} else {
@@ -215,6 +312,7 @@ func renderConnectionSummary(m *Model) string {
 	}
 	lines = append(lines, fmt.Sprintf("To: %s", to))
 	lines = append(lines, "Search")
+	lines = append(lines, "Options: "+searchOptionsLabel(m.searchOpts))
 
 	var sb strings.Builder
 	for i, line := range lines {
@@ -228,15 +326,38 @@ func renderConnectionSummary(m *Model) string {
 }
 
 func renderConnectionDetails(conn *api.Connection) string {
+	return renderConnectionDetailsLayout(conn, detailsLayout{cursor: -1, class: 2})
+}
+
+// detailsLayout controls how renderConnectionDetailsLayout presents a
+// connection.
+type detailsLayout struct {
+	cursor   int          // selected journey section, -1 for none
+	expanded map[int]bool // sections listing their intermediate stops
+	class    int          // travel class for occupancy forecasts
+	width    int          // terminal width, 0 if unknown
+}
+
+// renderConnectionDetailsLayout renders the connection details with the
+// journey section at the cursor marked and the intermediate stops of the
+// expanded sections listed. Station names are shortened to fit the width.
+func renderConnectionDetailsLayout(conn *api.Connection, l detailsLayout) string {
 	if conn == nil {
 		return ""
 	}
+	cursor, expanded, class := l.cursor, l.expanded, l.class
+	name := func(n string) string {
+		if l.width <= 0 {
+			return n
+		}
+		return truncate(n, max(l.width-40, 12))
+	}
 
 	var s strings.Builder
 
 	// Header with journey overview
 	s.WriteString("🚂 Connection Details 🚂\n")
-	s.WriteString("═══════════════════════════════════════════════════════════\n\n")
+	s.WriteString(ruler("═", l.width) + "\n\n")
 
 	// Journey summary
 	totalDuration := conn.Duration
@@ -248,7 +369,7 @@ func renderConnectionDetails(conn *api.Connection) string {
 
 	changes := countChanges(conn.Sections)
 
-	s.WriteString(fmt.Sprintf("📍 %s → %s\n", conn.From.Station.Name, conn.To.Station.Name))
+	s.WriteString(fmt.Sprintf("📍 %s → %s\n", name(conn.From.Station.Name), name(conn.To.Station.Name)))
 	var delays []string
 	if conn.From.Delay > 0 {
 		delays = append(delays, formatDelay(conn.From.Delay))
@@ -261,15 +382,24 @@ func renderConnectionDetails(conn *api.Connection) string {
 		delayStr = fmt.Sprintf(", Delay: %s", strings.Join(delays, "/"))
 	}
 	s.WriteString(fmt.Sprintf("🕐 %s → %s (Duration: %s%s)\n",
-		formatISOTime(conn.From.Departure),
-		formatISOTime(conn.To.Arrival),
+		expectedTime(conn.From.Departure, conn.From.Prognosis.Departure),
+		expectedTime(conn.To.Arrival, conn.To.Prognosis.Arrival),
 		totalDuration,
 		delayStr))
-	s.WriteString(fmt.Sprintf("🔄 %s\n\n", formatChanges(changes)))
+	risk := ""
+	if t, ok := connectionRisk(conn); ok {
+		risk = ", " + riskNote(t)
+	}
+	s.WriteString(fmt.Sprintf("🔄 %s%s\n\n", formatChanges(changes), risk))
 
 	// Detailed journey sections
 	s.WriteString("Journey Details:\n")
-	s.WriteString("───────────────────────────────────────────────────────────\n")
+	s.WriteString(ruler("─", l.width) + "\n")
+
+	changesAt := make(map[int]transfer)
+	for _, t := range transfers(conn) {
+		changesAt[t.section] = t
+	}
 
 	for i, section := range conn.Sections {
 		// Walking section
@@ -277,30 +407,36 @@ func renderConnectionDetails(conn *api.Connection) string {
 			mins := section.Walk.Duration / 60
 			walkDuration := fmt.Sprintf("%d min", mins)
 			s.WriteString(fmt.Sprintf("🚶 Walk from %s to %s (%s)\n\n",
-				section.Departure.Station.Name,
-				section.Arrival.Station.Name,
+				name(section.Departure.Station.Name),
+				name(section.Arrival.Station.Name),
 				walkDuration))
 			continue
 		}
 
 		// Transport section
 		if section.Journey != nil {
-			// Departure info
-			depPlatform := section.Departure.Platform
-			if depPlatform == "" {
-				depPlatform = section.Departure.Prognosis.Platform
-			}
-
-			// Arrival info
-			arrPlatform := section.Arrival.Platform
-			if arrPlatform == "" {
-				arrPlatform = section.Arrival.Prognosis.Platform
-			}
+			// Departure and arrival platforms, highlighted when changed
+			depPlatform := platformLabel(section.Departure)
+			arrPlatform := platformLabel(section.Arrival)
 
 			// Train info
 			trainLabel := fmt.Sprintf("%s%s", section.Journey.Category, section.Journey.Number)
+			cancelled := section.Departure.Cancelled || section.Arrival.Cancelled
+			if cancelled {
+				trainLabel = cancelledStyle.Render(strike(trainLabel)) + " ✖ cancelled"
+			}
 
-			s.WriteString(fmt.Sprintf("🚊 %s towards %s\n", trainLabel, section.Journey.To))
+			marker := ""
+			if cursor >= 0 {
+				marker = "  "
+			}
+			if t, ok := changesAt[i]; ok {
+				s.WriteString(marker + renderTransfer(t) + "\n")
+			}
+			if i == cursor {
+				marker = "> "
+			}
+			s.WriteString(fmt.Sprintf("%s🚊 %s towards %s\n", marker, trainLabel, name(section.Journey.To)))
 			delayDepStr := ""
 			if section.Departure.Delay > 0 {
 				delayDepStr = fmt.Sprintf(", Delay %s", formatDelay(section.Departure.Delay))
@@ -318,13 +454,26 @@ func renderConnectionDetails(conn *api.Connection) string {
 				arrLabel = fmt.Sprintf("Platform %s", arrPlatform)
 			}
 			s.WriteString(fmt.Sprintf("   Depart: %s from %s (%s%s)\n",
-				formatISOTime(section.Departure.Departure),
-				section.Departure.Station.Name,
+				expectedTime(section.Departure.Departure, section.Departure.Prognosis.Departure),
+				name(section.Departure.Station.Name),
 				depLabel,
 				delayDepStr))
+			if stops := intermediateStops(section); cursor >= 0 && len(stops) > 0 {
+				if expanded[i] {
+					for _, stop := range stops {
+						s.WriteString(renderPassStop(stop))
+					}
+				} else {
+					plural := "s"
+					if len(stops) == 1 {
+						plural = ""
+					}
+					s.WriteString(fmt.Sprintf("   ⋯ %d intermediate stop%s\n", len(stops), plural))
+				}
+			}
 			s.WriteString(fmt.Sprintf("   Arrive: %s at %s (%s%s)\n",
-				formatISOTime(section.Arrival.Arrival),
-				section.Arrival.Station.Name,
+				expectedTime(section.Arrival.Arrival, section.Arrival.Prognosis.Arrival),
+				name(section.Arrival.Station.Name),
 				arrLabel,
 				delayArrStr))
 
@@ -333,6 +482,9 @@ func renderConnectionDetails(conn *api.Connection) string {
 			if sectionDuration != "-" {
 				s.WriteString(fmt.Sprintf("   Duration: %s\n", sectionDuration))
 			}
+			if level := capacityFor(section.Journey.Capacity1st, section.Journey.Capacity2nd, class); level > 0 {
+				s.WriteString(fmt.Sprintf("   Occupancy: %s %s (class %d)\n", formatCapacity(level), capacityLabel(level), class))
+			}
 
 			if i < len(conn.Sections)-1 {
 				s.WriteString("\n")
@@ -340,11 +492,32 @@ func renderConnectionDetails(conn *api.Connection) string {
 		}
 	}
 
-	s.WriteString("\n───────────────────────────────────────────────────────────\n")
+	s.WriteString("\n" + ruler("─", l.width) + "\n")
 
 	return s.String()
 }
 
+// renderPassStop renders one intermediate stop with its scheduled and, when
+// different, expected time and platform.
+func renderPassStop(stop api.Stop) string {
+	scheduled := stop.Arrival
+	expected := stop.Prognosis.Arrival
+	if scheduled == "" {
+		scheduled = stop.Departure
+		expected = stop.Prognosis.Departure
+	}
+	timeStr := expectedTime(scheduled, expected)
+	platform := ""
+	if label := platformLabel(stop); label != "" {
+		platform = ", Platform " + label
+	}
+	name := stop.Station.Name
+	if stop.Cancelled {
+		name = cancelledStyle.Render(strike(name)) + " (no stop)"
+	}
+	return fmt.Sprintf("     · %s %s%s\n", timeStr, name, platform)
+}
+
 func renderConnectionsHeader(from string, via []string, to, info string) string {
 	var lines []string
 	lines = append(lines, "🔍 Connections")
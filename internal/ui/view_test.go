@@ -6,8 +6,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	api "SBBuddy/internal/api"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestRenderConnectionDetails(t *testing.T) {
@@ -61,3 +64,53 @@ func TestRenderConnectionDetailsNoPlatform(t *testing.T) {
 		t.Errorf("expected no platform label when platform missing")
 	}
 }
+
+func TestStationboardViewCachedNotice(t *testing.T) {
+	m := InitialModel()
+	m.state = stateShowStationboard
+	m.stationboard = &api.StationboardResponse{
+		Station:      api.Location{Name: "Chur"},
+		Stationboard: []api.StationboardEntry{{Name: "RE", Category: "RE", Number: "1"}},
+	}
+	if strings.Contains(m.View(), "Offline") {
+		t.Errorf("live results must not show the offline notice")
+	}
+	m.stationboard.CachedAt = time.Now()
+	if !strings.Contains(m.View(), "Offline: showing cached data") {
+		t.Errorf("cached results should be marked")
+	}
+}
+
+func TestConnectionDetailsExpandStops(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "connections.json"))
+	if err != nil {
+		t.Fatalf("read connections.json: %v", err)
+	}
+	var cr api.ConnectionsResponse
+	if err := json.Unmarshal(data, &cr); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	m := InitialModel()
+	m.connections = &cr
+	m.connTable = buildConnectionsTable(&cr, 2, 0)
+	m.state = stateShowConnections
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	out := m.View()
+	if !strings.Contains(out, "2 intermediate stops") || strings.Contains(out, "Landquart") {
+		t.Fatalf("expected collapsed stop list:\n%s", out)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	out = m.View()
+	for _, c := range []string{"12:39 → 12:41 Landquart, Platform 3 (was 2)", "12:55 Sargans, Platform 4"} {
+		if !strings.Contains(out, c) {
+			t.Errorf("output missing %q:\n%s", c, out)
+		}
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.detailCursor != 1 {
+		t.Errorf("expected cursor on second section, got %d", m.detailCursor)
+	}
+}
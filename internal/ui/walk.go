@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+)
+
+const (
+	// leaveSoon is how far ahead departures are marked that you have to
+	// leave for.
+	leaveSoon = 5 * time.Minute
+	// walkDelayMargin is how much earlier than the end of the walk boards
+	// start, so that trains running late enough to catch are listed.
+	walkDelayMargin = 15 * time.Minute
+)
+
+// walkState is the walk to the station of a favorite stationboard. It only
+// applies while the board is searched from now on.
+type walkState struct {
+	station string
+	minutes int
+	on      bool
+}
+
+// walkView is what buildStationboardTable needs to render when to leave.
+type walkView struct {
+	now     time.Time
+	station string
+	d       time.Duration
+}
+
+// applies reports whether the walk leads to the station of e. On combined
+// boards it only leads to one of the stations.
+func (w *walkView) applies(sb *api.StationboardResponse, e api.StationboardEntry) bool {
+	if sb.Arrivals {
+		return false
+	}
+	return !sb.Combined() || strings.EqualFold(sb.EntryStation(e).Name, w.station)
+}
+
+// catchable reports whether e leaves after the walk to its station is over.
+func (w *walkView) catchable(sb *api.StationboardResponse, e api.StationboardEntry) bool {
+	if !w.applies(sb, e) {
+		return true
+	}
+	t, err := expectedBoardTime(sb, e)
+	return err != nil || !t.Before(w.now.Add(w.d))
+}
+
+// leave renders when to leave for e, marked with "»" within leaveSoon.
+func (w *walkView) leave(sb *api.StationboardResponse, e api.StationboardEntry) string {
+	if !w.applies(sb, e) || e.Stop.Cancelled {
+		return "-"
+	}
+	t, err := expectedBoardTime(sb, e)
+	if err != nil {
+		return "-"
+	}
+	at := t.Add(-w.d)
+	s := countdown(at, w.now)
+	if at.Sub(w.now) < leaveSoon {
+		s = "» " + s
+	}
+	return s
+}
+
+// startWalk sets up the walk to the station of a favorite.
+func (m *Model) startWalk(fav store.Favorite) {
+	m.walk = walkState{}
+	if !fav.IsRoute() && fav.WalkMinutes > 0 {
+		m.walk = walkState{station: fav.From(), minutes: fav.WalkMinutes, on: true}
+	}
+}
+
+// walkView returns the walk of the current board, nil if none applies.
+func (m *Model) walkView() *walkView {
+	if !m.walk.on || m.walk.minutes <= 0 || m.arrivals {
+		return nil
+	}
+	return &walkView{now: m.now(), station: m.walk.station, d: time.Duration(m.walk.minutes) * time.Minute}
+}
+
+// walkStart returns the date and time a board from now on is fetched for:
+// empty for now, or the start of the walk to a single station.
+func (m *Model) walkStart() (string, string) {
+	w := m.walkView()
+	if w == nil || len(m.boardStations) != 1 {
+		return "", ""
+	}
+	t := WalkStart(w.now, w.d)
+	return t.Format("2006-01-02"), t.Format("15:04")
+}
+
+// WalkStart returns when a board is fetched from for a walk of d to the
+// station: the API lists departures by their scheduled time, so the board
+// starts a margin for delays before the walk is over, and not before now.
+func WalkStart(now time.Time, d time.Duration) time.Time {
+	return now.Add(max(d-walkDelayMargin, 0))
+}
+
+// dropUnreachable removes the departures that leave before the walk to
+// their station is over.
+func (m *Model) dropUnreachable() {
+	if w := m.walkView(); w != nil {
+		dropUnreachable(m.stationboard, w)
+	}
+}
+
+// DropUnreachable removes the departures of sb expected to leave before a
+// walk of d from now to station is over.
+func DropUnreachable(sb *api.StationboardResponse, station string, now time.Time, d time.Duration) {
+	dropUnreachable(sb, &walkView{now: now, station: station, d: d})
+}
+
+func dropUnreachable(sb *api.StationboardResponse, w *walkView) {
+	if sb == nil {
+		return
+	}
+	entries := sb.Stationboard[:0:0]
+	for _, e := range sb.Stationboard {
+		if w.catchable(sb, e) {
+			entries = append(entries, e)
+		}
+	}
+	sb.Stationboard = entries
+}
+
+// walkNote describes the walk above the stationboard, or returns "".
+func (m *Model) walkNote() string {
+	if m.walkView() == nil {
+		return ""
+	}
+	return fmt.Sprintf("🚶 %d min walk to %s: only departures you can still catch\n", m.walk.minutes, m.walk.station)
+}
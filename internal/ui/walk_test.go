@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	api "SBBuddy/internal/api"
+	"SBBuddy/internal/store"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// walkProvider serves a fixed board from the requested time on, by
+// scheduled departure like the API, and records the requested times.
+type walkProvider struct {
+	*api.FixtureProvider
+	board []api.StationboardEntry
+	times []string
+}
+
+func (p *walkProvider) Stationboard(ctx context.Context, station, date, timeStr string, opts api.StationboardOptions) (*api.StationboardResponse, error) {
+	p.times = append(p.times, timeStr)
+	sb := &api.StationboardResponse{Station: api.Location{Name: station}, Stationboard: []api.StationboardEntry{}}
+	for _, e := range p.board {
+		if timeStr == "" || e.Stop.Departure[11:16] >= timeStr {
+			sb.Stationboard = append(sb.Stationboard, e)
+		}
+	}
+	return sb, nil
+}
+
+func TestWalkToFavoriteStation(t *testing.T) {
+	st, _ := store.Open("")
+	p := &walkProvider{
+		FixtureProvider: api.NewFixtureProvider(filepath.Join("..", "..", "testdata")),
+		board: []api.StationboardEntry{
+			liveEntry("1", "12:03", 0, ""),
+			liveEntry("2", "12:05", 3, ""),
+			liveEntry("3", "12:12", 0, ""),
+			liveEntry("4", "12:30", 0, ""),
+		},
+	}
+	m := NewModel(api.NewClientWithProvider(p), st)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	m.now = func() time.Time { return now }
+	cmd := m.openFavorite(store.Favorite{Name: "home", Stations: []string{"Bern"}, WalkMinutes: 7})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+
+	// a short walk is within the margin for delays, the board starts now
+	if p.times[0] != "12:00" {
+		t.Errorf("expected the board to start now, got %q", p.times[0])
+	}
+	rows := m.sbTable.Rows()
+	if len(rows) != 3 {
+		t.Fatalf("expected the S1 to be hidden, got %v", rows)
+	}
+	// the S2 is scheduled during the walk but leaves late at 12:08, one
+	// minute after you could be there
+	if rows[0][1] != "» in 1 min" || rows[1][1] != "in 5 min" || rows[2][1] != "in 23 min" {
+		t.Errorf("unexpected leave column %q, %q, %q", rows[0][1], rows[1][1], rows[2][1])
+	}
+	if !strings.Contains(m.View(), "7 min walk to Bern") {
+		t.Errorf("expected a walk note:\n%s", m.View())
+	}
+
+	// choosing a time shows every departure again
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(cmd().(tea.BatchMsg)[0]())
+	if rows := m.sbTable.Rows(); len(rows) != 4 || len(rows[0]) != 5 {
+		t.Errorf("expected the full board without a leave column, got %v", rows)
+	}
+}
+
+func TestWalkStart(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for walk, want := range map[time.Duration]string{
+		7 * time.Minute:  "12:00",
+		15 * time.Minute: "12:00",
+		40 * time.Minute: "12:25",
+	} {
+		if got := WalkStart(now, walk).Format("15:04"); got != want {
+			t.Errorf("WalkStart(%v) = %s, want %s", walk, got, want)
+		}
+	}
+
+	// a late train scheduled before the walk is over is kept
+	sb := &api.StationboardResponse{Stationboard: []api.StationboardEntry{
+		liveEntry("1", "12:30", 0, ""),
+		liveEntry("2", "12:35", 10, ""),
+		liveEntry("3", "12:50", 0, ""),
+	}}
+	local := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	DropUnreachable(sb, "Bern", local, 40*time.Minute)
+	if len(sb.Stationboard) != 2 || sb.Stationboard[0].Number != "2" {
+		t.Errorf("expected the delayed S2 and the S3, got %+v", sb.Stationboard)
+	}
+}
+
+func TestSaveFavoriteWalk(t *testing.T) {
+	m := InitialModel()
+	m.state = stateShowStationboard
+	m.boardStations = []string{"Chur"}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	for i := 0; i < 6; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if !strings.Contains(m.View(), "Walk to the station: 5 min") {
+		t.Errorf("expected the walk in the form:\n%s", m.View())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if fav, ok := m.store.Favorites.Find("Chur"); !ok || fav.WalkMinutes != 5 {
+		t.Errorf("unexpected favorite %+v", fav)
+	}
+}